@@ -4,6 +4,7 @@ import (
 	"html/template"
 	"log"
 	"os"
+	"runtime"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -57,23 +58,42 @@ func main() {
 		Use:   "build",
 		Short: "Build the static site",
 		Run: func(cmd *cobra.Command, args []string) {
+			jobs, _ := cmd.Flags().GetInt("jobs")
+
 			config, err := loadConfig()
 			if err != nil {
 				log.Fatal(err)
 			}
 
+			// Minification set in config applies unless the flags are given
+			minify, _ := cmd.Flags().GetBool("minify")
+			if !cmd.Flags().Changed("minify") {
+				assets, _ := config["assets"].(map[string]interface{})
+				minify = assets["minify"] == true
+			}
+			minifyHTML, _ := cmd.Flags().GetBool("minify-html")
+			if !cmd.Flags().Changed("minify-html") {
+				minifyHTML = config["minify_html"] == true
+			}
+
 			site := &domain.Site{
 				PagesDir:         "pages",
 				TemplatesDir:     "templates",
 				AssetsDir:        "assets",
+				DataDir:          "data",
+				I18nDir:          "i18n",
 				DistDir:          "dist",
 				EnableAutoReload: false,
 				Config:           config,
 				ConfigPath:       "config.yaml",
+				CacheDir:         ".stw",
+				Jobs:             jobs,
+				Minify:           minify,
+				MinifyHTML:       minifyHTML,
 			}
 
 			fs := &infrastructure.OSFileSystem{}
-			renderer := &infrastructure.GoTemplateRenderer{}
+			renderer := &infrastructure.GoTemplateRenderer{FS: fs}
 
 			builder := application.NewSiteBuilder(site, fs, renderer)
 
@@ -89,6 +109,8 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			port, _ := cmd.Flags().GetString("port")
 			watch, _ := cmd.Flags().GetBool("watch")
+			drafts, _ := cmd.Flags().GetBool("drafts")
+			jobs, _ := cmd.Flags().GetInt("jobs")
 
 			config, err := loadConfig()
 			if err != nil {
@@ -99,14 +121,19 @@ func main() {
 				PagesDir:         "pages",
 				TemplatesDir:     "templates",
 				AssetsDir:        "assets",
+				DataDir:          "data",
+				I18nDir:          "i18n",
 				DistDir:          "dist",
 				EnableAutoReload: watch,
 				Config:           config,
 				ConfigPath:       "config.yaml",
+				CacheDir:         ".stw",
+				Jobs:             jobs,
+				BuildDrafts:      drafts,
 			}
 
 			fs := &infrastructure.OSFileSystem{}
-			renderer := &infrastructure.GoTemplateRenderer{}
+			renderer := &infrastructure.GoTemplateRenderer{FS: fs}
 
 			builder := application.NewSiteBuilder(site, fs, renderer)
 
@@ -117,8 +144,14 @@ func main() {
 		},
 	}
 
+	buildCmd.Flags().IntP("jobs", "j", runtime.GOMAXPROCS(0), "Number of pages to render in parallel")
+	buildCmd.Flags().Bool("minify", false, "Minify CSS, JavaScript, JSON and SVG assets")
+	buildCmd.Flags().Bool("minify-html", false, "Minify rendered HTML pages")
+
 	serveCmd.Flags().StringP("port", "p", "8080", "Port to serve on")
 	serveCmd.Flags().BoolP("watch", "w", true, "Enable auto-reload on file changes")
+	serveCmd.Flags().Bool("drafts", true, "Include pages marked draft")
+	serveCmd.Flags().IntP("jobs", "j", runtime.GOMAXPROCS(0), "Number of pages to render in parallel")
 
 	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(buildCmd)
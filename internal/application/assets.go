@@ -0,0 +1,194 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// assetsManifestFile maps asset paths to their fingerprinted URLs
+	assetsManifestFile = "assets-manifest.json"
+	// fingerprintLength is the number of hex digits of the content hash in
+	// fingerprinted file names
+	fingerprintLength = 8
+)
+
+// cssURL matches a url() reference in a stylesheet
+var cssURL = regexp.MustCompile(`url\(\s*['"]?([^'")\s]+)['"]?\s*\)`)
+
+// assetsConfig returns the assets config block
+func (sb *SiteBuilder) assetsConfig() map[string]interface{} {
+	config, _ := sb.site.Config["assets"].(map[string]interface{})
+	return config
+}
+
+// fingerprinting reports whether assets are written under fingerprinted names,
+// set with fingerprint: true in the assets config block
+func (sb *SiteBuilder) fingerprinting() bool {
+	return sb.assetsConfig()["fingerprint"] == true
+}
+
+// loadAssets lists the files of the assets directory before pages are
+// rendered, so templates can link them with the asset function. With
+// fingerprinting, each file is named after a hash of its content, such as
+// css/style.3f9a1c2b.css, and url() references between assets in stylesheets
+// are rewritten to the fingerprinted names. Stylesheets are named after their
+// rewritten content so they change when an asset they reference changes, and
+// minified assets after their minified content.
+func (sb *SiteBuilder) loadAssets() error {
+	sb.assets = map[string]string{}
+	sb.assetContent = map[string][]byte{}
+	sb.assetsHash = ""
+	if sb.site.AssetsDir == "" || !sb.exists(sb.site.AssetsDir) {
+		return nil
+	}
+
+	var files, stylesheets []string
+	err := sb.fs.WalkDir(sb.site.AssetsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(sb.site.AssetsDir, path)
+		if filepath.Ext(rel) == ".css" {
+			stylesheets = append(stylesheets, filepath.ToSlash(rel))
+		} else {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fingerprint := sb.fingerprinting()
+	for _, rel := range append(files, stylesheets...) {
+		if !fingerprint {
+			sb.assets[rel] = rel
+			continue
+		}
+		content, err := sb.fs.ReadFile(filepath.Join(sb.site.AssetsDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		changed := false
+		if path.Ext(rel) == ".css" {
+			content = rewriteCSSURLs(content, rel, sb.assets)
+			changed = true
+		}
+		if sb.site.Minify && minifiable[strings.ToLower(path.Ext(rel))] {
+			if content, err = sb.minifyAsset(rel, content); err != nil {
+				return err
+			}
+			changed = true
+		}
+		if changed {
+			sb.assetContent[rel] = content
+		}
+		sb.assets[rel] = fingerprintName(rel, content)
+	}
+
+	manifest, err := json.Marshal(sb.assets)
+	if err != nil {
+		return err
+	}
+	sb.assetsHash = hashBytes(manifest)
+	return nil
+}
+
+// minifiable are the extensions of the assets minified with Site.Minify
+var minifiable = map[string]bool{".css": true, ".js": true, ".json": true, ".svg": true}
+
+// minifyAsset minifies a CSS, JavaScript, JSON or SVG asset when the site is
+// built with minification, and returns other content unchanged
+func (sb *SiteBuilder) minifyAsset(rel string, content []byte) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(rel))
+	if !sb.site.Minify || !minifiable[ext] {
+		return content, nil
+	}
+	minified, err := sb.minifier.Minify(ext, content)
+	if err != nil {
+		return nil, fmt.Errorf("minify %s: %w", filepath.Join(sb.site.AssetsDir, rel), err)
+	}
+	return minified, nil
+}
+
+// fingerprintName inserts a hash of an asset's content before its extension
+func fingerprintName(rel string, content []byte) string {
+	ext := path.Ext(rel)
+	return strings.TrimSuffix(rel, ext) + "." + hashBytes(content)[:fingerprintLength] + ext
+}
+
+// rewriteCSSURLs replaces url() references of a stylesheet to other assets,
+// relative or under /assets/, with their names in assets. Other references,
+// such as data URIs and external URLs, are kept.
+func rewriteCSSURLs(css []byte, rel string, assets map[string]string) []byte {
+	return cssURL.ReplaceAllFunc(css, func(match []byte) []byte {
+		ref := string(cssURL.FindSubmatch(match)[1])
+		target, suffix := ref, ""
+		if i := strings.IndexAny(target, "?#"); i >= 0 {
+			target, suffix = target[:i], target[i:]
+		}
+		var name string
+		switch {
+		case strings.HasPrefix(target, "/assets/"):
+			name = strings.TrimPrefix(target, "/assets/")
+		case target == "" || strings.HasPrefix(target, "/") || strings.Contains(target, ":"):
+			return match
+		default:
+			name = path.Join(path.Dir(rel), target)
+		}
+		output, ok := assets[name]
+		if !ok {
+			return match
+		}
+		fingerprinted := strings.TrimSuffix(target, path.Base(target)) + path.Base(output)
+		return []byte("url(" + fingerprinted + suffix + ")")
+	})
+}
+
+// assetURL returns the URL of a file in the assets directory, such as
+// /assets/css/style.3f9a1c2b.css for css/style.css with fingerprinting. It is
+// available to templates as the asset function.
+func (sb *SiteBuilder) assetURL(name string) (string, error) {
+	rel := strings.TrimPrefix(strings.TrimPrefix(name, "/"), "assets/")
+	output, ok := sb.assets[rel]
+	if !ok {
+		return "", fmt.Errorf("asset %q not found in %s", name, sb.site.AssetsDir)
+	}
+	return "/assets/" + output, nil
+}
+
+// assetOutput returns the path of an asset relative to the assets output
+// directory
+func (sb *SiteBuilder) assetOutput(rel string) string {
+	if output, ok := sb.assets[filepath.ToSlash(rel)]; ok {
+		return filepath.FromSlash(output)
+	}
+	return rel
+}
+
+// writeAssetsManifest writes assets-manifest.json, which maps the path of
+// every asset to its fingerprinted URL for other tools. It is only written
+// with fingerprinting.
+func (sb *SiteBuilder) writeAssetsManifest() error {
+	if !sb.fingerprinting() {
+		return nil
+	}
+	manifest := make(map[string]string, len(sb.assets))
+	for rel, output := range sb.assets {
+		manifest[rel] = "/assets/" + output
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return sb.writeFile(filepath.Join(sb.outDir, assetsManifestFile), append(content, '\n'))
+}
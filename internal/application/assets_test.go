@@ -0,0 +1,180 @@
+package application
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+func assetTestBuilder(fingerprint bool) (*SiteBuilder, *MockFileSystem) {
+	fs := NewMockFileSystem()
+	fs.dirs["static"] = true
+	fs.files["static/images/logo.png"] = []byte("png")
+	fs.files["static/js/app.js"] = []byte("console.log(1)")
+	fs.files["static/css/style.css"] = []byte(`body { background: url("../images/logo.png"); }
+h1 { background: url(/assets/images/logo.png?v=2); }
+.icon { background: url(data:image/png;base64,AAAA); }
+.cdn { background: url(https://cdn.example.com/bg.png); }
+`)
+	site := &domain.Site{
+		AssetsDir: "static",
+		DistDir:   "dist",
+		Config: map[string]interface{}{
+			"assets": map[string]interface{}{"fingerprint": fingerprint},
+		},
+	}
+	return newTestBuilder(site, fs, NewMockTemplateRenderer()), fs
+}
+
+func TestLoadAssets_Fingerprint(t *testing.T) {
+	builder, fs := assetTestBuilder(true)
+	if err := builder.loadAssets(); err != nil {
+		t.Fatalf("loadAssets failed: %v", err)
+	}
+
+	logo := "images/logo." + hashBytes([]byte("png"))[:fingerprintLength] + ".png"
+	if builder.assets["images/logo.png"] != logo {
+		t.Errorf("Fingerprinted logo = %q, expected %q", builder.assets["images/logo.png"], logo)
+	}
+	css := string(builder.assetContent["css/style.css"])
+	for _, expected := range []string{
+		"url(../" + logo + ")",
+		"url(/assets/" + logo + "?v=2)",
+		"url(data:image/png;base64,AAAA)",
+		"url(https://cdn.example.com/bg.png)",
+	} {
+		if !strings.Contains(css, expected) {
+			t.Errorf("Expected the stylesheet to contain %s, got %s", expected, css)
+		}
+	}
+	if got := builder.assets["css/style.css"]; got != fingerprintName("css/style.css", []byte(css)) {
+		t.Errorf("Expected the stylesheet to be named after its rewritten content, got %q", got)
+	}
+
+	url, err := builder.assetURL("js/app.js")
+	if err != nil || !strings.HasPrefix(url, "/assets/js/app.") || !strings.HasSuffix(url, ".js") || url == "/assets/js/app.js" {
+		t.Errorf("assetURL(js/app.js) = %q, %v", url, err)
+	}
+	if other, _ := builder.assetURL("/assets/js/app.js"); other != url {
+		t.Errorf("Expected the /assets/ prefix to be accepted, got %q", other)
+	}
+	if _, err := builder.assetURL("js/missing.js"); err == nil || !strings.Contains(err.Error(), `asset "js/missing.js" not found`) {
+		t.Errorf("Expected a missing asset error, got %v", err)
+	}
+
+	if err := builder.copyAssets(); err != nil {
+		t.Fatalf("copyAssets failed: %v", err)
+	}
+	if got := fs.written["dist/assets/"+builder.assets["css/style.css"]]; got == nil || got.String() != css {
+		t.Errorf("Expected the rewritten stylesheet under its fingerprinted name, got %v", got)
+	}
+	if fs.written["dist/assets/js/app.js"] != nil {
+		t.Error("Expected assets to be written only under their fingerprinted names")
+	}
+
+	if err := builder.writeAssetsManifest(); err != nil {
+		t.Fatalf("writeAssetsManifest failed: %v", err)
+	}
+	var manifest map[string]string
+	if err := json.Unmarshal(fs.written["dist/assets-manifest.json"].Bytes(), &manifest); err != nil {
+		t.Fatalf("Invalid manifest: %v", err)
+	}
+	if len(manifest) != 3 || manifest["images/logo.png"] != "/assets/"+logo || manifest["js/app.js"] != url {
+		t.Errorf("Unexpected manifest %v", manifest)
+	}
+}
+
+func TestLoadAssets_NoFingerprint(t *testing.T) {
+	builder, fs := assetTestBuilder(false)
+	if err := builder.loadAssets(); err != nil {
+		t.Fatalf("loadAssets failed: %v", err)
+	}
+	if url, err := builder.assetURL("css/style.css"); err != nil || url != "/assets/css/style.css" {
+		t.Errorf("assetURL(css/style.css) = %q, %v", url, err)
+	}
+	if err := builder.writeAssetsManifest(); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.copyAssets(); err != nil {
+		t.Fatal(err)
+	}
+	if fs.written["dist/assets-manifest.json"] != nil {
+		t.Error("Expected no manifest without fingerprinting")
+	}
+	if !strings.Contains(fs.written["dist/assets/css/style.css"].String(), `url("../images/logo.png")`) {
+		t.Error("Expected assets to be copied unchanged without fingerprinting")
+	}
+}
+
+func TestBuildPages_AssetFunction(t *testing.T) {
+	builder, fs := assetTestBuilder(true)
+	builder.site.PagesDir = "content"
+	fs.files["content/index.html"] = []byte(`<script src="{{asset "js/app.js"}}"></script>`)
+	fs.files["content/broken.html"] = []byte(`<img src="{{asset "images/missing.png"}}">`)
+	if err := builder.loadAssets(); err != nil {
+		t.Fatal(err)
+	}
+	renderer := NewMockTemplateRenderer()
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	err := builder.buildPages(tmpl, meta.Meta{})
+	if err == nil || !strings.Contains(err.Error(), `asset "images/missing.png" not found`) {
+		t.Errorf("Expected a missing asset error, got %v", err)
+	}
+	expected := `src="/assets/` + builder.assets["js/app.js"] + `"`
+	if got := fs.written["dist/index.html"].String(); !strings.Contains(got, expected) {
+		t.Errorf("Expected the page to link %s, got %q", expected, got)
+	}
+}
+
+func TestCopyAssets_Minify(t *testing.T) {
+	builder, fs := assetTestBuilder(false)
+	builder.site.Minify = true
+	fs.files["static/js/app.js"] = []byte("// Log\nconsole.log( 1 )\n")
+	if err := builder.loadAssets(); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.copyAssets(); err != nil {
+		t.Fatalf("copyAssets failed: %v", err)
+	}
+
+	if got := fs.written["dist/assets/js/app.js"].String(); got != "console.log(1)" {
+		t.Errorf("Expected the script to be minified, got %q", got)
+	}
+	if got := fs.written["dist/assets/css/style.css"].String(); !strings.HasPrefix(got, `body{background:url("../images/logo.png")}h1{`) {
+		t.Errorf("Expected the stylesheet to be minified, got %q", got)
+	}
+	if got := fs.written["dist/assets/images/logo.png"].String(); got != "png" {
+		t.Errorf("Expected other assets to be copied unchanged, got %q", got)
+	}
+
+	fs.files["static/data.json"] = []byte("{broken")
+	if err := builder.copyAssets(); err == nil || !strings.Contains(err.Error(), "minify "+filepath.Join("static", "data.json")) {
+		t.Errorf("Expected a minify error naming the file, got %v", err)
+	}
+}
+
+func TestLoadAssets_FingerprintMinified(t *testing.T) {
+	builder, fs := assetTestBuilder(true)
+	builder.site.Minify = true
+	if err := builder.loadAssets(); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.copyAssets(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rel := range []string{"css/style.css", "js/app.js"} {
+		content := fs.written["dist/assets/"+builder.assets[rel]].Bytes()
+		if builder.assets[rel] != fingerprintName(rel, content) {
+			t.Errorf("Expected %s to be named after its minified content, got %q", rel, builder.assets[rel])
+		}
+	}
+	if css := fs.written["dist/assets/"+builder.assets["css/style.css"]].String(); strings.Contains(css, "\n") || !strings.Contains(css, builder.assets["images/logo.png"]) {
+		t.Errorf("Expected a rewritten and minified stylesheet, got %q", css)
+	}
+}
@@ -0,0 +1,104 @@
+package application
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+
+	"github.com/EmiraLabs/stw-cli/internal/infrastructure"
+)
+
+// cacheFile is the name of the build manifest inside the cache directory
+const cacheFile = "cache.json"
+
+// cacheEntry records the inputs an output file was built from
+type cacheEntry struct {
+	Source        string `json:"source"`
+	SourceHash    string `json:"source_hash"`
+	TemplatesHash string `json:"templates_hash,omitempty"`
+	ConfigHash    string `json:"config_hash,omitempty"`
+	DepsHash      string `json:"deps_hash,omitempty"` // other pages listed by the page
+}
+
+// buildCache is a content-hash manifest of every output in the dist directory,
+// keyed by output path relative to the dist directory. Outputs whose recorded
+// inputs match the current inputs are not rebuilt, and outputs that are not
+// recorded again are dropped from the next build. A nil cache treats every
+// output as stale.
+type buildCache struct {
+	previous map[string]cacheEntry
+	current  map[string]cacheEntry
+	mu       sync.Mutex
+}
+
+// manifest is the on-disk representation of the build cache
+type manifest struct {
+	Outputs map[string]cacheEntry `json:"outputs"`
+}
+
+// loadBuildCache reads the manifest from dir. A missing or unreadable manifest
+// results in an empty cache, forcing a full build.
+func loadBuildCache(fs infrastructure.FileSystem, dir string) *buildCache {
+	cache := &buildCache{
+		previous: map[string]cacheEntry{},
+		current:  map[string]cacheEntry{},
+	}
+	data, err := fs.ReadFile(filepath.Join(dir, cacheFile))
+	if err != nil {
+		return cache
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil || m.Outputs == nil {
+		return cache
+	}
+	cache.previous = m.Outputs
+	return cache
+}
+
+// save writes the manifest of the current build to dir
+func (c *buildCache) save(fs infrastructure.FileSystem, dir string) error {
+	if c == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(manifest{Outputs: c.current}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := fs.Create(filepath.Join(dir, cacheFile))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	return err
+}
+
+// fresh reports whether output was last built from exactly the given inputs
+func (c *buildCache) fresh(output string, entry cacheEntry) bool {
+	if c == nil {
+		return false
+	}
+	prev, ok := c.previous[output]
+	return ok && prev == entry
+}
+
+// record stores the inputs output was built from in the current build
+func (c *buildCache) record(output string, entry cacheEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current[output] = entry
+}
+
+// hashBytes returns the hex-encoded SHA-256 of data
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,46 @@
+package application
+
+import (
+	"testing"
+)
+
+func TestLoadBuildCache_Missing(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.files[".stw/cache.json"] = []byte("not json")
+	cache := loadBuildCache(fs, ".stw")
+	if len(cache.previous) != 0 {
+		t.Errorf("Expected empty cache for invalid manifest, got %v", cache.previous)
+	}
+}
+
+func TestBuildCache_SaveAndLoad(t *testing.T) {
+	fs := NewMockFileSystem()
+	cache := loadBuildCache(fs, ".stw")
+	entry := cacheEntry{Source: "pages/index.html", SourceHash: "abc", TemplatesHash: "def", ConfigHash: "ghi"}
+	cache.record("index.html", entry)
+	if err := cache.save(fs, ".stw"); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	fs.files[".stw/cache.json"] = fs.written[".stw/cache.json"].Bytes()
+	loaded := loadBuildCache(fs, ".stw")
+	if !loaded.fresh("index.html", entry) {
+		t.Error("Expected recorded entry to be fresh")
+	}
+	changed := entry
+	changed.ConfigHash = "other"
+	if loaded.fresh("index.html", changed) {
+		t.Error("Expected entry with changed config hash to be stale")
+	}
+}
+
+func TestBuildCache_Nil(t *testing.T) {
+	var cache *buildCache
+	if cache.fresh("index.html", cacheEntry{}) {
+		t.Error("Nil cache should treat outputs as stale")
+	}
+	cache.record("index.html", cacheEntry{})
+	if err := cache.save(NewMockFileSystem(), ".stw"); err != nil {
+		t.Errorf("Nil cache save failed: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package application
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// loadData loads every data file under the data directory into a nested map
+// keyed by path without extension, so data/team/members.yaml is available as
+// .Data.team.members. A missing data directory loads no data.
+func (sb *SiteBuilder) loadData() error {
+	sb.data = map[string]interface{}{}
+	sb.dataHash = ""
+	if sb.site.DataDir == "" || !sb.exists(sb.site.DataDir) {
+		return nil
+	}
+
+	var hashes []byte
+	err := sb.fs.WalkDir(sb.site.DataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isDataFile(d.Name()) {
+			return nil
+		}
+
+		content, err := sb.fs.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		value, err := decodeData(path, content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		rel, _ := filepath.Rel(sb.site.DataDir, path)
+		keys := strings.Split(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))), "/")
+		if err := setData(sb.data, keys, value); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		hashes = append(hashes, path...)
+		hashes = append(hashes, hashBytes(content)...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sb.dataHash = hashBytes(hashes)
+	return nil
+}
+
+// isDataFile reports whether a file in the data directory should be loaded
+func isDataFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml", ".json", ".toml", ".csv":
+		return true
+	}
+	return false
+}
+
+// decodeData decodes a data file by its extension. CSV files become a list of
+// records keyed by the header row.
+func decodeData(path string, content []byte) (interface{}, error) {
+	var value interface{}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, &value); err != nil {
+			return nil, err
+		}
+	case ".json":
+		if err := json.Unmarshal(content, &value); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		var table map[string]interface{}
+		if err := toml.Unmarshal(content, &table); err != nil {
+			return nil, err
+		}
+		value = table
+	case ".csv":
+		return decodeCSV(content)
+	}
+	return value, nil
+}
+
+// decodeCSV decodes CSV content whose first row holds the column names
+func decodeCSV(content []byte) ([]interface{}, error) {
+	rows, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	records := []interface{}{}
+	if len(rows) == 0 {
+		return records, nil
+	}
+	header := rows[0]
+	for _, row := range rows[1:] {
+		record := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if i < len(row) {
+				record[column] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// setData stores value in data under the nested keys. A file next to a
+// directory of the same name, such as team.yaml and team/, is merged with the
+// directory's files when it holds a map.
+func setData(data map[string]interface{}, keys []string, value interface{}) error {
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := data[key].(map[string]interface{})
+		if !ok {
+			if _, exists := data[key]; exists {
+				return fmt.Errorf("data key %q is already set by another file", strings.Join(keys, "."))
+			}
+			next = map[string]interface{}{}
+			data[key] = next
+		}
+		data = next
+	}
+
+	key := keys[len(keys)-1]
+	existing, exists := data[key]
+	if !exists {
+		data[key] = value
+		return nil
+	}
+	existingMap, ok1 := existing.(map[string]interface{})
+	valueMap, ok2 := value.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return fmt.Errorf("data key %q is already set by another file", strings.Join(keys, "."))
+	}
+	for k, v := range valueMap {
+		if _, exists := existingMap[k]; exists {
+			return fmt.Errorf("data key %q is already set by another file", strings.Join(append(keys, k), "."))
+		}
+		existingMap[k] = v
+	}
+	return nil
+}
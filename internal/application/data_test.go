@@ -0,0 +1,89 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+func TestLoadData(t *testing.T) {
+	site := &domain.Site{DataDir: "data"}
+	fs := NewMockFileSystem()
+	fs.dirs["data"] = true
+	fs.files["data/site.json"] = []byte(`{"name": "Example", "founded": 2020}`)
+	fs.files["data/team.yaml"] = []byte("lead: Ada\n")
+	fs.files["data/team/members.yaml"] = []byte("- name: Ada\n  role: Lead\n- name: Linus\n  role: Kernel\n")
+	fs.files["data/products.csv"] = []byte("slug,name,price\nwidget,Widget,9.99\ngadget,Gadget,19.99\n")
+	fs.files["data/settings/build.toml"] = []byte("[output]\nminify = true\n")
+	fs.files["data/notes.txt"] = []byte("ignored")
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	if err := builder.loadData(); err != nil {
+		t.Fatalf("loadData failed: %v", err)
+	}
+
+	if builder.data["site"].(map[string]interface{})["name"] != "Example" {
+		t.Errorf("Unexpected JSON data: %v", builder.data["site"])
+	}
+	team := builder.data["team"].(map[string]interface{})
+	if team["lead"] != "Ada" {
+		t.Errorf("Expected team.yaml to be merged with team/, got %v", team)
+	}
+	members := team["members"].([]interface{})
+	if len(members) != 2 || members[1].(map[string]interface{})["name"] != "Linus" {
+		t.Errorf("Unexpected YAML data: %v", members)
+	}
+	products := builder.data["products"].([]interface{})
+	if len(products) != 2 || products[0].(map[string]interface{})["price"] != "9.99" {
+		t.Errorf("Unexpected CSV data: %v", products)
+	}
+	output := builder.data["settings"].(map[string]interface{})["build"].(map[string]interface{})["output"].(map[string]interface{})
+	if output["minify"] != true {
+		t.Errorf("Unexpected TOML data: %v", output)
+	}
+	if _, ok := builder.data["notes"]; ok {
+		t.Error("Expected unsupported files to be skipped")
+	}
+	if builder.dataHash == "" {
+		t.Error("Expected a data hash")
+	}
+
+	// Changing a data file changes the hash
+	hash := builder.dataHash
+	fs.files["data/team.yaml"] = []byte("lead: Grace\n")
+	if err := builder.loadData(); err != nil {
+		t.Fatalf("loadData failed: %v", err)
+	}
+	if builder.dataHash == hash {
+		t.Error("Expected the data hash to change")
+	}
+}
+
+func TestLoadData_Errors(t *testing.T) {
+	tests := map[string]map[string][]byte{
+		"invalid YAML": {"data/a.yaml": []byte("key: [unclosed")},
+		"invalid CSV":  {"data/a.csv": []byte("a,b\n\"unclosed")},
+		"conflict":     {"data/a.json": []byte(`[1, 2]`), "data/a.yaml": []byte("key: value")},
+	}
+	for name, files := range tests {
+		fs := NewMockFileSystem()
+		fs.dirs["data"] = true
+		for path, content := range files {
+			fs.files[path] = content
+		}
+		builder := newTestBuilder(&domain.Site{DataDir: "data"}, fs, NewMockTemplateRenderer())
+		if err := builder.loadData(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoadData_MissingDir(t *testing.T) {
+	builder := newTestBuilder(&domain.Site{DataDir: "data"}, NewMockFileSystem(), NewMockTemplateRenderer())
+	if err := builder.loadData(); err != nil {
+		t.Fatalf("loadData failed: %v", err)
+	}
+	if builder.data == nil || len(builder.data) != 0 {
+		t.Errorf("Expected empty data, got %v", builder.data)
+	}
+}
@@ -0,0 +1,66 @@
+package application
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+// draftMarker is shown at the top of draft pages served with stw serve
+const draftMarker = `<div class="stw-draft" style="position:fixed;top:0;left:0;right:0;z-index:2147483647;padding:4px 8px;background:#f59e0b;color:#111;font:bold 13px/1.4 system-ui,sans-serif;text-align:center">Draft: not published by stw build</div>`
+
+// skippedPage is a page left out of the build and why
+type skippedPage struct {
+	path   string
+	reason string
+}
+
+// unpublished returns why a page should be left out of the build: it is a
+// draft and drafts are not built, its publish date (or else its date) is in
+// the future, or its expiry date has passed. It returns an empty string for
+// published pages.
+func (sb *SiteBuilder) unpublished(page *domain.Page) string {
+	now := sb.now()
+	published := page.PublishDate
+	if published.IsZero() {
+		published = page.Date
+	}
+	switch {
+	case page.Draft && !sb.site.BuildDrafts:
+		return "draft"
+	case published.After(now):
+		return fmt.Sprintf("publish date %s is in the future", published.Format("2006-01-02"))
+	case !page.ExpiryDate.IsZero() && !page.ExpiryDate.After(now):
+		return fmt.Sprintf("expired on %s", page.ExpiryDate.Format("2006-01-02"))
+	}
+	return ""
+}
+
+// reportSkipped logs the pages left out of the build
+func (sb *SiteBuilder) reportSkipped() {
+	if len(sb.skipped) == 0 {
+		return
+	}
+	log.Printf("Skipped %d unpublished pages:", len(sb.skipped))
+	for _, skipped := range sb.skipped {
+		log.Printf("  %s (%s)", skipped.path, skipped.reason)
+	}
+}
+
+// markDraft inserts the draft marker at the start of the body of a rendered
+// page, or at the start of the page when it has no body tag
+func markDraft(html []byte) []byte {
+	at := 0
+	lower := bytes.ToLower(html)
+	if i := bytes.Index(lower, []byte("<body")); i >= 0 {
+		if end := bytes.IndexByte(html[i:], '>'); end >= 0 {
+			at = i + end + 1
+		}
+	}
+	marked := make([]byte, 0, len(html)+len(draftMarker))
+	marked = append(marked, html[:at]...)
+	marked = append(marked, draftMarker...)
+	return append(marked, html[at:]...)
+}
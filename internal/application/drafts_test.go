@@ -0,0 +1,106 @@
+package application
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+func draftTestBuilder(buildDrafts bool) (*SiteBuilder, *MockFileSystem) {
+	fs := NewMockFileSystem()
+	fs.files["content/index.html"] = []byte("<p>Home</p>")
+	fs.files["content/blog/draft.md"] = []byte("---\ndraft: true\n---\nWork in progress")
+	fs.files["content/blog/scheduled.md"] = []byte("---\npublish_date: 2024-07-01\n---\nSoon")
+	fs.files["content/blog/future.md"] = []byte("---\ndate: 2024-08-01\n---\nLater")
+	fs.files["content/blog/expired.md"] = []byte("---\nexpiry_date: 2024-05-01\n---\nGone")
+	fs.files["content/blog/live.md"] = []byte("---\ndate: 2024-05-01\nexpiry_date: 2025-01-01\n---\nLive")
+	site := &domain.Site{PagesDir: "content", DistDir: "dist", BuildDrafts: buildDrafts}
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+	builder.now = func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+	return builder, fs
+}
+
+func TestLoadPages_Unpublished(t *testing.T) {
+	builder, _ := draftTestBuilder(false)
+
+	sources, err := builder.loadPages(meta.Meta{})
+	if err != nil {
+		t.Fatalf("loadPages failed: %v", err)
+	}
+
+	var paths []string
+	for _, src := range sources {
+		paths = append(paths, src.page.Path)
+	}
+	if len(paths) != 2 || paths[0] != "blog/live.html" || paths[1] != "index.html" {
+		t.Errorf("Loaded pages = %v, expected only the published pages", paths)
+	}
+
+	expected := map[string]string{
+		"content/blog/draft.md":     "draft",
+		"content/blog/scheduled.md": "publish date 2024-07-01 is in the future",
+		"content/blog/future.md":    "publish date 2024-08-01 is in the future",
+		"content/blog/expired.md":   "expired on 2024-05-01",
+	}
+	if len(builder.skipped) != len(expected) {
+		t.Fatalf("Skipped = %+v", builder.skipped)
+	}
+	for _, skipped := range builder.skipped {
+		if expected[skipped.path] != skipped.reason {
+			t.Errorf("Skipped %s because %q, expected %q", skipped.path, skipped.reason, expected[skipped.path])
+		}
+	}
+}
+
+func TestLoadPages_BuildDrafts(t *testing.T) {
+	builder, _ := draftTestBuilder(true)
+
+	sources, err := builder.loadPages(meta.Meta{})
+	if err != nil {
+		t.Fatalf("loadPages failed: %v", err)
+	}
+	var draft *domain.Page
+	for _, src := range sources {
+		if src.page.Path == "blog/draft.html" {
+			draft = src.page
+		}
+	}
+	if draft == nil || !draft.Draft {
+		t.Fatal("Expected the draft page to be loaded when drafts are built")
+	}
+	if len(builder.skipped) != 3 {
+		t.Errorf("Expected future and expired pages to still be skipped, got %+v", builder.skipped)
+	}
+}
+
+func TestRenderPage_DraftMarker(t *testing.T) {
+	builder, fs := draftTestBuilder(true)
+	renderer := NewMockTemplateRenderer()
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	if err := builder.buildPages(tmpl, meta.Meta{}); err != nil {
+		t.Fatalf("buildPages failed: %v", err)
+	}
+	if got := fs.written["dist/blog/draft.html"].String(); !strings.HasPrefix(got, draftMarker) {
+		t.Errorf("Expected the draft to be marked, got %q", got)
+	}
+	if got := fs.written["dist/blog/live.html"].String(); strings.Contains(got, draftMarker) {
+		t.Errorf("Published pages should not be marked, got %q", got)
+	}
+}
+
+func TestMarkDraft(t *testing.T) {
+	tests := map[string]string{
+		`<html><BODY class="post"><p>Hi</p></BODY></html>`: `<html><BODY class="post">` + draftMarker + `<p>Hi</p></BODY></html>`,
+		`<p>Fragment</p>`: draftMarker + `<p>Fragment</p>`,
+	}
+	for html, expected := range tests {
+		if got := string(markDraft([]byte(html))); got != expected {
+			t.Errorf("markDraft(%q) = %q, expected %q", html, got, expected)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"html/template"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+const (
+	rssFile          = "index.xml"
+	atomFile         = "atom.xml"
+	jsonFeedFile     = "feed.json"
+	defaultFeedLimit = 20
+	summaryLength    = 300 // maximum length of summaries derived from content
+)
+
+// feedFormats are the feed formats a section can enable, in the order they
+// are written
+var feedFormats = []string{"rss", "atom", "json"}
+
+// feedConfig is the feed configuration of a section under the feeds config key
+type feedConfig struct {
+	limit   int
+	summary bool
+	formats []string
+}
+
+// feedSettings reads the feed configuration of a section:
+//
+//	feeds:
+//	  blog:
+//	    limit: 10
+//	    content: summary
+//	    formats: [rss, atom]
+//
+// Feeds hold the 20 newest pages with their full content in every format by
+// default.
+func feedSettings(value interface{}) (feedConfig, error) {
+	settings, _ := value.(map[string]interface{})
+	limit, err := numberParam(settings, "limit")
+	if err != nil {
+		return feedConfig{}, err
+	}
+	cfg := feedConfig{
+		limit:   limit,
+		formats: stringList(settings["formats"]),
+	}
+	if cfg.limit < 1 {
+		cfg.limit = defaultFeedLimit
+	}
+	if len(cfg.formats) == 0 {
+		cfg.formats = feedFormats
+	}
+	for _, format := range cfg.formats {
+		if !slices.Contains(feedFormats, format) {
+			return cfg, fmt.Errorf("unknown feed format %q, expected one of %s", format, strings.Join(feedFormats, ", "))
+		}
+	}
+
+	switch content := configString(settings["content"]); content {
+	case "", "full":
+	case "summary":
+		cfg.summary = true
+	default:
+		return cfg, fmt.Errorf("unknown feed content %q, expected full or summary", content)
+	}
+	return cfg, nil
+}
+
+// feedItem is a page as it appears in a feed
+type feedItem struct {
+	title     string
+	url       string
+	summary   string
+	content   template.HTML
+	published time.Time
+	updated   time.Time
+}
+
+// feed is the data shared by every format of a section's feed
+type feed struct {
+	title       string
+	description string
+	url         string // absolute URL of the section
+	dir         string // section directory relative to the output directory
+	dirURL      string // absolute URL of dir, where the feed files are served
+	updated     time.Time
+	items       []feedItem
+}
+
+// writeFeeds writes the feeds of every section listed under the feeds config
+// key, keyed by section directory such as blog. Feeds are written next to the
+// section's list page as index.xml (RSS), atom.xml and feed.json, and hold
+// the section's pages newest first. Multilingual sites get a feed per
+// language, such as /de/blog/index.xml.
+func (sb *SiteBuilder) writeFeeds(tmpl *template.Template, sources []*pageSource) error {
+	feeds, _ := sb.site.Config["feeds"].(map[string]interface{})
+	if len(feeds) == 0 {
+		return nil
+	}
+	baseURL := sb.baseURL()
+	if baseURL == "" {
+		return fmt.Errorf("feeds require base_url to be set in config")
+	}
+
+	pageSources := make(map[*domain.Page]*pageSource)
+	lists := make(map[string][]*pageSource)
+	for _, src := range sources {
+		pageSources[src.page] = src
+		if filepath.Base(src.treePath) == domain.IndexFile && src.page.Pages != nil {
+			dir := filepath.ToSlash(filepath.Dir(languageRel(src.page.Language, src.treePath)))
+			lists[dir] = append(lists[dir], src)
+		}
+	}
+
+	sections := make([]string, 0, len(feeds))
+	for section := range feeds {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		dir := strings.Trim(section, "/")
+		if dir == "" {
+			dir = "."
+		}
+		if len(lists[dir]) == 0 {
+			return fmt.Errorf("feed for %q: section has no list page", section)
+		}
+		cfg, err := feedSettings(feeds[section])
+		if err != nil {
+			return fmt.Errorf("feed for %q: %w", section, err)
+		}
+		for _, list := range lists[dir] {
+			if err := sb.writeFeed(tmpl, list, pageSources, baseURL, cfg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeFeed writes the feeds of one list page in every configured format
+func (sb *SiteBuilder) writeFeed(tmpl *template.Template, list *pageSource, pageSources map[*domain.Page]*pageSource, baseURL string, cfg feedConfig) error {
+	f := feed{
+		title:       itemTitle(list.page),
+		description: list.page.Meta.Description,
+		url:         baseURL + list.page.URL,
+		dir:         filepath.Dir(list.page.Path),
+		dirURL:      baseURL + dirURL(list.page),
+	}
+	var err error
+	if f.items, err = sb.feedItems(tmpl, list.page.Pages, pageSources, baseURL, cfg); err != nil {
+		return err
+	}
+	for _, item := range f.items {
+		if item.updated.After(f.updated) {
+			f.updated = item.updated
+		}
+	}
+
+	for _, format := range cfg.formats {
+		switch format {
+		case "rss":
+			err = sb.writeRSS(f, cfg)
+		case "atom":
+			err = sb.writeAtom(f, cfg)
+		case "json":
+			err = sb.writeJSONFeed(f, cfg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// feedItems returns the newest pages of a section as feed items, with their
+// bodies rendered. The list pages of subsections are left out; index pages
+// without pages of their own, such as a post with its images in a directory,
+// are items like any other page.
+func (sb *SiteBuilder) feedItems(tmpl *template.Template, pages []*domain.Page, pageSources map[*domain.Page]*pageSource, baseURL string, cfg feedConfig) ([]feedItem, error) {
+	pages = slices.DeleteFunc(slices.Clone(pages), func(page *domain.Page) bool {
+		return len(page.Pages) > 0
+	})
+	sort.SliceStable(pages, func(i, j int) bool {
+		return publishDate(pages[i]).After(publishDate(pages[j]))
+	})
+	if len(pages) > cfg.limit {
+		pages = pages[:cfg.limit]
+	}
+
+	var items []feedItem
+	for _, page := range pages {
+		src, ok := pageSources[page]
+		if !ok {
+			continue
+		}
+		content, err := sb.pageContent(tmpl, src, page)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src.path, err)
+		}
+		summary := stringParam(page.Params, "description")
+		if summary == "" {
+			summary = summarize(content)
+		}
+		updated := page.Lastmod
+		if updated.Before(publishDate(page)) {
+			updated = publishDate(page)
+		}
+		items = append(items, feedItem{
+			title:     itemTitle(page),
+			url:       baseURL + page.URL,
+			summary:   summary,
+			content:   content,
+			published: publishDate(page),
+			updated:   updated,
+		})
+	}
+	return items, nil
+}
+
+// itemTitle returns the title a page is listed with: the title from its
+// front matter, or else the title derived from its path
+func itemTitle(page *domain.Page) string {
+	if stringParam(page.Params, "title") != "" {
+		return page.Meta.Title
+	}
+	return page.Title
+}
+
+// publishDate returns the date a page was published: its publish_date or date
+// front matter key, or else its modification time
+func publishDate(page *domain.Page) time.Time {
+	switch {
+	case !page.PublishDate.IsZero():
+		return page.PublishDate
+	case !page.Date.IsZero():
+		return page.Date
+	}
+	return page.Lastmod
+}
+
+// summarize returns the text of rendered HTML, cut at a word boundary when it
+// is longer than summaryLength
+func summarize(content template.HTML) string {
+	var text strings.Builder
+	inTag := false
+	for _, r := range string(content) {
+		switch {
+		case r == '<':
+			inTag = true
+			text.WriteRune(' ')
+		case r == '>':
+			inTag = false
+		case !inTag:
+			text.WriteRune(r)
+		}
+	}
+
+	words := strings.Fields(html.UnescapeString(text.String()))
+	var summary string
+	for i, word := range words {
+		if len(summary)+len(word)+1 > summaryLength {
+			return summary + "…"
+		}
+		if i > 0 {
+			summary += " "
+		}
+		summary += word
+	}
+	return summary
+}
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomXmlns string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate,omitempty"`
+	Description string `xml:"description"`
+}
+
+func (sb *SiteBuilder) writeRSS(f feed, cfg feedConfig) error {
+	rss := rssFeed{
+		Version:   "2.0",
+		AtomXmlns: "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         f.title,
+			Link:          f.url,
+			Description:   f.description,
+			LastBuildDate: rssDate(f.updated),
+			AtomLink:      atomLink{Href: f.dirURL + rssFile, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	for _, item := range f.items {
+		description := item.summary
+		if !cfg.summary {
+			description = string(item.content)
+		}
+		rss.Channel.Items = append(rss.Channel.Items, rssItem{
+			Title:       item.title,
+			Link:        item.url,
+			GUID:        item.url,
+			PubDate:     rssDate(item.published),
+			Description: description,
+		})
+	}
+	return sb.writeXML(filepath.Join(f.dir, rssFile), rss)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string       `xml:"title"`
+	Link      atomLink     `xml:"link"`
+	ID        string       `xml:"id"`
+	Published string       `xml:"published,omitempty"`
+	Updated   string       `xml:"updated"`
+	Summary   string       `xml:"summary,omitempty"`
+	Content   *atomContent `xml:"content,omitempty"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func (sb *SiteBuilder) writeAtom(f feed, cfg feedConfig) error {
+	atom := atomFeed{
+		Xmlns: "http://www.w3.org/2005/Atom",
+		Title: f.title,
+		Links: []atomLink{
+			{Href: f.url},
+			{Href: f.dirURL + atomFile, Rel: "self", Type: "application/atom+xml"},
+		},
+		ID:      f.url,
+		Updated: sitemapDate(f.updated),
+	}
+	for _, item := range f.items {
+		entry := atomEntry{
+			Title:     item.title,
+			Link:      atomLink{Href: item.url},
+			ID:        item.url,
+			Published: sitemapDate(item.published),
+			Updated:   sitemapDate(item.updated),
+			Summary:   item.summary,
+		}
+		if !cfg.summary {
+			entry.Content = &atomContent{Type: "html", Body: string(item.content)}
+		}
+		atom.Entries = append(atom.Entries, entry)
+	}
+	return sb.writeXML(filepath.Join(f.dir, atomFile), atom)
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html,omitempty"`
+	ContentText   string `json:"content_text,omitempty"`
+	Summary       string `json:"summary,omitempty"`
+	DatePublished string `json:"date_published,omitempty"`
+	DateModified  string `json:"date_modified,omitempty"`
+}
+
+func (sb *SiteBuilder) writeJSONFeed(f feed, cfg feedConfig) error {
+	jf := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.title,
+		HomePageURL: f.url,
+		FeedURL:     f.dirURL + jsonFeedFile,
+		Description: f.description,
+		Items:       []jsonFeedItem{},
+	}
+	for _, item := range f.items {
+		entry := jsonFeedItem{
+			ID:            item.url,
+			URL:           item.url,
+			Title:         item.title,
+			Summary:       item.summary,
+			DatePublished: sitemapDate(item.published),
+			DateModified:  sitemapDate(item.updated),
+		}
+		if cfg.summary {
+			entry.ContentText = item.summary
+		} else {
+			entry.ContentHTML = string(item.content)
+		}
+		jf.Items = append(jf.Items, entry)
+	}
+
+	// Keep HTML content readable instead of escaping <, > and &
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(jf); err != nil {
+		return err
+	}
+	return sb.writeFile(filepath.Join(sb.outDir, f.dir, jsonFeedFile), buf.Bytes())
+}
+
+// rssDate formats a time as RSS expects, or returns an empty string for the
+// zero time
+func rssDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC1123Z)
+}
@@ -0,0 +1,184 @@
+package application
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+func feedTestSources() []*pageSource {
+	blog := testSource("blog/index.html", map[string]interface{}{"title": "Blog"})
+	blog.page.Meta.Title = "Blog"
+	older := testSource("blog/older.html", map[string]interface{}{"date": "2024-01-01", "title": "Older"})
+	older.page.Meta.Title = "Older"
+	older.body = "<p>Older &amp; wiser</p>"
+	newer := testSource("blog/newer.md", map[string]interface{}{"date": "2024-06-01", "description": "The newest post"})
+	newer.isMarkdown = true
+	newer.body = "# Newer"
+	newer.page.Path = "blog/newer.html"
+	newer.page.URL = "/blog/newer.html"
+	blog.page.Pages = []*domain.Page{older.page, newer.page}
+	return []*pageSource{blog, older, newer}
+}
+
+func TestWriteFeeds(t *testing.T) {
+	site := &domain.Site{
+		DistDir: "dist",
+		Config: map[string]interface{}{
+			"base_url": template.HTML("https://example.com"),
+			"feeds":    map[string]interface{}{"blog": nil},
+		},
+	}
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	if err := builder.writeFeeds(template.New("test"), feedTestSources()); err != nil {
+		t.Fatalf("writeFeeds failed: %v", err)
+	}
+
+	rss := fs.written["dist/blog/index.xml"].String()
+	for _, expected := range []string{
+		"<title>Blog</title>",
+		`<atom:link href="https://example.com/blog/index.xml" rel="self"`,
+		"<link>https://example.com/blog/newer.html</link>",
+		"<pubDate>Sat, 01 Jun 2024 00:00:00 +0000</pubDate>",
+		"&lt;p&gt;Older &amp;amp; wiser&lt;/p&gt;",
+	} {
+		if !strings.Contains(rss, expected) {
+			t.Errorf("Expected RSS feed to contain %q:\n%s", expected, rss)
+		}
+	}
+	if strings.Index(rss, "Newer") > strings.Index(rss, "Older") {
+		t.Error("Expected newest item first")
+	}
+
+	atom := fs.written["dist/blog/atom.xml"].String()
+	if !strings.Contains(atom, "<summary>The newest post</summary>") || !strings.Contains(atom, `<content type="html">`) {
+		t.Errorf("Unexpected Atom feed:\n%s", atom)
+	}
+
+	var jf jsonFeed
+	if err := json.Unmarshal(fs.written["dist/blog/feed.json"].Bytes(), &jf); err != nil {
+		t.Fatalf("Invalid JSON feed: %v", err)
+	}
+	if len(jf.Items) != 2 || jf.Items[1].Title != "Older" || jf.Items[1].ContentHTML != "<p>Older &amp; wiser</p>" || jf.Items[1].Summary != "Older & wiser" {
+		t.Errorf("Unexpected JSON feed items: %+v", jf.Items)
+	}
+}
+
+func TestWriteFeeds_SummaryAndLimit(t *testing.T) {
+	site := &domain.Site{
+		DistDir: "dist",
+		Config: map[string]interface{}{
+			"base_url": template.HTML("https://example.com"),
+			"feeds": map[string]interface{}{
+				"/blog/": map[string]interface{}{
+					"limit":   template.HTML("1"),
+					"content": template.HTML("summary"),
+					"formats": []interface{}{template.HTML("json")},
+				},
+			},
+		},
+	}
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	if err := builder.writeFeeds(template.New("test"), feedTestSources()); err != nil {
+		t.Fatalf("writeFeeds failed: %v", err)
+	}
+	if _, ok := fs.written["dist/blog/index.xml"]; ok {
+		t.Error("Expected no RSS feed when only json is enabled")
+	}
+
+	var jf jsonFeed
+	if err := json.Unmarshal(fs.written["dist/blog/feed.json"].Bytes(), &jf); err != nil {
+		t.Fatalf("Invalid JSON feed: %v", err)
+	}
+	if len(jf.Items) != 1 || jf.Items[0].ContentHTML != "" || jf.Items[0].ContentText != "The newest post" {
+		t.Errorf("Unexpected JSON feed items: %+v", jf.Items)
+	}
+}
+
+func TestWriteFeeds_FileURLAndSubsections(t *testing.T) {
+	site := &domain.Site{
+		DistDir: "dist",
+		Config: map[string]interface{}{
+			"base_url": template.HTML("https://example.com"),
+			"feeds":    map[string]interface{}{"blog": nil},
+		},
+	}
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	sources := feedTestSources()
+	blog := sources[0]
+	blog.page.Path = "blog.html"
+	blog.page.URL = "/blog.html"
+	archive := testSource("blog/archive/index.html", map[string]interface{}{"date": "2024-12-01", "title": "Archive"})
+	archive.page.Pages = []*domain.Page{testSource("blog/archive/old.html", nil).page}
+	blog.page.Pages = append(blog.page.Pages, archive.page)
+	sources = append(sources, archive)
+
+	if err := builder.writeFeeds(template.New("test"), sources); err != nil {
+		t.Fatalf("writeFeeds failed: %v", err)
+	}
+	rss := fs.written["dist/index.xml"].String()
+	if !strings.Contains(rss, `<atom:link href="https://example.com/index.xml" rel="self"`) {
+		t.Errorf("Expected the self link to point at the written feed:\n%s", rss)
+	}
+	if strings.Contains(rss, "Archive") {
+		t.Errorf("Expected subsection list pages to be left out:\n%s", rss)
+	}
+
+	var jf jsonFeed
+	if err := json.Unmarshal(fs.written["dist/feed.json"].Bytes(), &jf); err != nil {
+		t.Fatalf("Invalid JSON feed: %v", err)
+	}
+	if jf.FeedURL != "https://example.com/feed.json" || jf.HomePageURL != "https://example.com/blog.html" || len(jf.Items) != 2 {
+		t.Errorf("Unexpected JSON feed: %+v", jf)
+	}
+}
+
+func TestWriteFeeds_Errors(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"missing base URL": {
+			"feeds": map[string]interface{}{"blog": nil},
+		},
+		"missing section": {
+			"base_url": "https://example.com",
+			"feeds":    map[string]interface{}{"news": nil},
+		},
+		"unknown format": {
+			"base_url": "https://example.com",
+			"feeds":    map[string]interface{}{"blog": map[string]interface{}{"formats": "rdf"}},
+		},
+		"limit not a number": {
+			"base_url": "https://example.com",
+			"feeds":    map[string]interface{}{"blog": map[string]interface{}{"limit": "ten"}},
+		},
+		"unknown content": {
+			"base_url": "https://example.com",
+			"feeds":    map[string]interface{}{"blog": map[string]interface{}{"content": "excerpt"}},
+		},
+	}
+	for name, config := range tests {
+		site := &domain.Site{DistDir: "dist", Config: config}
+		builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+		if err := builder.writeFeeds(template.New("test"), feedTestSources()); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	if got := summarize("<h1>Title</h1><p>Some &lt;b&gt; text</p>"); got != "Title Some <b> text" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+	long := template.HTML(strings.Repeat("word ", 100))
+	if got := summarize(long); len(got) > summaryLength+len("…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("Expected truncated summary, got %q", got)
+	}
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+// writeHeaders writes the headers file from the headers config block, which
+// maps path patterns to the headers of the responses for matching paths:
+//
+//	headers:
+//	  /*:
+//	    X-Content-Type-Options: nosniff
+//	  /assets/*:
+//	    Cache-Control: public, max-age=31536000
+//
+// A list of values is joined with commas. Nothing is written when there is no
+// headers block.
+func (sb *SiteBuilder) writeHeaders() error {
+	rules, err := sb.headerRules()
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	for _, rule := range rules {
+		b.WriteString(rule.Path + "\n")
+		names := make([]string, 0, len(rule.Headers))
+		for name := range rule.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			b.WriteString("  " + name + ": " + rule.Headers.Get(name) + "\n")
+		}
+	}
+	return sb.writeFile(filepath.Join(sb.outDir, domain.HeadersFile), []byte(b.String()))
+}
+
+// headerRules returns the rules of the headers config block sorted by path
+func (sb *SiteBuilder) headerRules() ([]domain.HeaderRule, error) {
+	config, _ := sb.site.Config["headers"].(map[string]interface{})
+	paths := make([]string, 0, len(config))
+	for path := range config {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var rules []domain.HeaderRule
+	var errs []error
+	for _, path := range paths {
+		if !strings.HasPrefix(path, "/") || strings.ContainsAny(path, " \t") {
+			errs = append(errs, fmt.Errorf("headers: path must start with /, got %q", path))
+			continue
+		}
+		entries, ok := config[path].(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Errorf("headers for %s must be a map of header names to values", path))
+			continue
+		}
+		rule := domain.HeaderRule{Path: path, Headers: http.Header{}}
+		for name, value := range entries {
+			joined := strings.Join(stringList(value), ", ")
+			if err := validateHeader(name, joined); err != nil {
+				errs = append(errs, fmt.Errorf("headers for %s: %w", path, err))
+				continue
+			}
+			rule.Headers.Set(name, joined)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, errors.Join(errs...)
+}
+
+// validateHeader checks that a header has a name without separators and a
+// value on a single line
+func validateHeader(name, value string) error {
+	if name == "" || strings.ContainsAny(name, " \t:\r\n") {
+		return fmt.Errorf("invalid header name %q", name)
+	}
+	if value == "" || strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("header %s needs a value on a single line", name)
+	}
+	return nil
+}
+
+// parseHeaders reads a headers file in Cloudflare Pages format: a path
+// pattern on its own line followed by indented "Name: value" lines, with #
+// comments
+func parseHeaders(content []byte) ([]domain.HeaderRule, error) {
+	var rules []domain.HeaderRule
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if trimmed == line {
+			rules = append(rules, domain.HeaderRule{Path: trimmed, Headers: http.Header{}})
+			continue
+		}
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok || len(rules) == 0 {
+			return nil, fmt.Errorf("line %d: expected an indented header below a path", n)
+		}
+		rules[len(rules)-1].Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return rules, scanner.Err()
+}
+
+// matchHeaders returns the headers of every rule matching a request path. A
+// header set by more than one rule gets all of their values, joined with
+// commas as on Cloudflare Pages.
+func matchHeaders(rules []domain.HeaderRule, path string) http.Header {
+	headers := http.Header{}
+	for _, rule := range rules {
+		if _, ok := matchPath(rule.Path, path); !ok {
+			continue
+		}
+		for name, values := range rule.Headers {
+			headers[name] = append(headers[name], values...)
+		}
+	}
+	for name, values := range headers {
+		headers[name] = []string{strings.Join(values, ", ")}
+	}
+	return headers
+}
@@ -0,0 +1,116 @@
+package application
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+func TestWriteHeaders(t *testing.T) {
+	site := &domain.Site{
+		DistDir: "dist",
+		Config: map[string]interface{}{
+			"headers": map[string]interface{}{
+				"/assets/*": map[string]interface{}{
+					"cache-control": template.HTML("public, max-age=31536000, immutable"),
+				},
+				"/*": map[string]interface{}{
+					"X-Frame-Options":        template.HTML("DENY"),
+					"X-Content-Type-Options": template.HTML("nosniff"),
+					"Permissions-Policy":     []interface{}{template.HTML("camera=()"), template.HTML("microphone=()")},
+				},
+			},
+		},
+	}
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	if err := builder.writeHeaders(); err != nil {
+		t.Fatalf("writeHeaders failed: %v", err)
+	}
+
+	expected := `/*
+  Permissions-Policy: camera=(), microphone=()
+  X-Content-Type-Options: nosniff
+  X-Frame-Options: DENY
+/assets/*
+  Cache-Control: public, max-age=31536000, immutable
+`
+	if got := fs.written["dist/_headers"].String(); got != expected {
+		t.Errorf("_headers = %q, expected %q", got, expected)
+	}
+}
+
+func TestWriteHeaders_NoConfig(t *testing.T) {
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(&domain.Site{DistDir: "dist"}, fs, NewMockTemplateRenderer())
+
+	if err := builder.writeHeaders(); err != nil {
+		t.Fatalf("writeHeaders failed: %v", err)
+	}
+	if fs.written["dist/_headers"] != nil {
+		t.Error("Expected no headers file without a headers block")
+	}
+}
+
+func TestWriteHeaders_Errors(t *testing.T) {
+	tests := []struct {
+		headers  map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"assets/*": map[string]interface{}{"X-A": "1"}}, `path must start with /, got "assets/*"`},
+		{map[string]interface{}{"/*": template.HTML("nosniff")}, "headers for /* must be a map"},
+		{map[string]interface{}{"/*": map[string]interface{}{"Bad Name": "1"}}, `invalid header name "Bad Name"`},
+		{map[string]interface{}{"/*": map[string]interface{}{"X-A": "a\nb"}}, "header X-A needs a value on a single line"},
+	}
+	for _, tt := range tests {
+		site := &domain.Site{DistDir: "dist", Config: map[string]interface{}{"headers": tt.headers}}
+		builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+		if err := builder.writeHeaders(); err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("writeHeaders(%v) error = %v, expected %q", tt.headers, err, tt.expected)
+		}
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	content := "# Security\n/*\n  X-Frame-Options: DENY\n\n/assets/*\n  Cache-Control: max-age=60\n  Link: </a.css>; rel=preload\n"
+	rules, err := parseHeaders([]byte(content))
+	if err != nil {
+		t.Fatalf("parseHeaders failed: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Path != "/*" || rules[1].Path != "/assets/*" {
+		t.Fatalf("parseHeaders = %+v", rules)
+	}
+	if rules[1].Headers.Get("Link") != "</a.css>; rel=preload" {
+		t.Errorf("Expected the value to keep its colons, got %q", rules[1].Headers.Get("Link"))
+	}
+
+	if _, err := parseHeaders([]byte("  X-Frame-Options: DENY\n")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected an error for a header without a path, got %v", err)
+	}
+}
+
+func TestMatchHeaders(t *testing.T) {
+	rules := []domain.HeaderRule{
+		{Path: "/*", Headers: http.Header{"X-Frame-Options": {"DENY"}, "Link": {"</a.css>"}}},
+		{Path: "/assets/*", Headers: http.Header{"Cache-Control": {"max-age=60"}, "Link": {"</b.css>"}}},
+		{Path: "/docs/:page/", Headers: http.Header{"X-Robots-Tag": {"noindex"}}},
+	}
+
+	headers := matchHeaders(rules, "/assets/css/app.css")
+	if headers.Get("X-Frame-Options") != "DENY" || headers.Get("Cache-Control") != "max-age=60" {
+		t.Errorf("Expected headers of both rules, got %v", headers)
+	}
+	if headers.Get("Link") != "</a.css>, </b.css>" {
+		t.Errorf("Expected values of both rules joined, got %q", headers.Get("Link"))
+	}
+	if headers := matchHeaders(rules, "/docs/install/"); headers.Get("X-Robots-Tag") != "noindex" {
+		t.Errorf("Expected the placeholder rule to match, got %v", headers)
+	}
+	if headers := matchHeaders(rules, "/docs/install/more/"); headers.Get("X-Robots-Tag") != "" || headers.Get("Cache-Control") != "" {
+		t.Errorf("Expected only the catch-all rule to match, got %v", headers)
+	}
+}
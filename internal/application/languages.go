@@ -0,0 +1,301 @@
+package application
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+	"gopkg.in/yaml.v3"
+)
+
+// loadLanguages reads the languages declared under the languages config key
+// and their translation catalogs from the i18n directory:
+//
+//	languages:
+//	  en: {name: English, weight: 1}
+//	  de: {name: Deutsch, weight: 2, meta: {description: ...}}
+//	default_language: en
+//
+// Languages are ordered by weight, then code. The default language is
+// default_language, or else the first language. Keys missing from a catalog
+// fall back to the default language's catalog. Sites without languages load
+// none.
+func (sb *SiteBuilder) loadLanguages() error {
+	sb.languages = nil
+	sb.languageMeta = nil
+	sb.i18nHash = ""
+	declared, _ := sb.site.Config["languages"].(map[string]interface{})
+	if len(declared) == 0 {
+		return nil
+	}
+
+	sb.languageMeta = make(map[string]meta.Meta, len(declared))
+	for code, value := range declared {
+		if code == "" || strings.ContainsAny(code, `/\.`) {
+			return fmt.Errorf("invalid language code %q", code)
+		}
+		settings, _ := value.(map[string]interface{})
+		lang := &domain.Language{
+			Code:   code,
+			Name:   configString(settings["name"]),
+			Weight: intParam(settings, "weight"),
+			URL:    "/" + code + "/",
+		}
+		if lang.Name == "" {
+			lang.Name = code
+		}
+		sb.languages = append(sb.languages, lang)
+		sb.languageMeta[code] = meta.LoadLanguageMeta(sb.site.Config, code)
+	}
+	sort.Slice(sb.languages, func(i, j int) bool {
+		a, b := sb.languages[i], sb.languages[j]
+		if a.Weight != b.Weight {
+			return a.Weight < b.Weight
+		}
+		return a.Code < b.Code
+	})
+
+	def := sb.languages[0]
+	if code := configString(sb.site.Config["default_language"]); code != "" {
+		if def = sb.language(code); def == nil {
+			return fmt.Errorf("default_language %q is not declared under languages", code)
+		}
+	}
+	def.Default = true
+
+	var hashes []byte
+	for _, lang := range sb.languages {
+		path := filepath.Join(sb.site.I18nDir, lang.Code+".yaml")
+		lang.Strings = map[string]string{}
+		if sb.site.I18nDir == "" || !sb.exists(path) {
+			continue
+		}
+		content, err := sb.fs.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var catalog map[string]interface{}
+		if err := yaml.Unmarshal(content, &catalog); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		flattenCatalog(lang.Strings, "", catalog)
+		hashes = append(hashes, path...)
+		hashes = append(hashes, hashBytes(content)...)
+	}
+	for _, lang := range sb.languages {
+		for key, s := range def.Strings {
+			if _, ok := lang.Strings[key]; !ok {
+				lang.Strings[key] = s
+			}
+		}
+	}
+	sb.i18nHash = hashBytes(hashes)
+	return nil
+}
+
+// flattenCatalog adds the translations of a catalog to translations, joining the
+// keys of nested maps with dots so nav: {home: Start} becomes nav.home
+func flattenCatalog(translations map[string]string, prefix string, catalog map[string]interface{}) {
+	for key, value := range catalog {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenCatalog(translations, prefix+key+".", v)
+		case nil:
+		default:
+			translations[prefix+key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// language returns the declared language with the given code, or nil
+func (sb *SiteBuilder) language(code string) *domain.Language {
+	for _, lang := range sb.languages {
+		if lang.Code == code {
+			return lang
+		}
+	}
+	return nil
+}
+
+// defaultLanguage returns the default language, or nil on sites without
+// languages
+func (sb *SiteBuilder) defaultLanguage() *domain.Language {
+	for _, lang := range sb.languages {
+		if lang.Default {
+			return lang
+		}
+	}
+	return nil
+}
+
+// pageLanguage returns the language of a page from its path relative to the
+// pages directory, and its path relative to that language's content tree.
+// Pages under a language directory, such as de/about/index.html, and pages
+// with a language suffix, such as about/index.de.html, belong to that
+// language; other pages belong to the default language.
+func (sb *SiteBuilder) pageLanguage(rel string) (*domain.Language, string) {
+	if len(sb.languages) == 0 {
+		return nil, rel
+	}
+	parts := strings.SplitN(filepath.ToSlash(rel), "/", 2)
+	if len(parts) == 2 {
+		if lang := sb.language(parts[0]); lang != nil {
+			return lang, filepath.FromSlash(parts[1])
+		}
+	}
+	ext := filepath.Ext(rel)
+	name := strings.TrimSuffix(rel, ext)
+	if lang := sb.language(strings.TrimPrefix(filepath.Ext(name), ".")); lang != nil {
+		return lang, strings.TrimSuffix(name, filepath.Ext(name)) + ext
+	}
+	return sb.defaultLanguage(), rel
+}
+
+// languageRel returns the path of a page relative to its language's content
+// tree, such as blog/post.html for de/blog/post.html
+func languageRel(lang *domain.Language, rel string) string {
+	if lang == nil {
+		return rel
+	}
+	return strings.TrimPrefix(rel, lang.Code+string(filepath.Separator))
+}
+
+// languagePath returns the output path of a page relative to its language's
+// content tree
+func languagePath(lang *domain.Language, rel string) string {
+	if lang == nil {
+		return rel
+	}
+	return filepath.Join(lang.Code, rel)
+}
+
+// siteMetaFor returns the site meta defaults of pages in lang
+func (sb *SiteBuilder) siteMetaFor(lang *domain.Language, siteMeta meta.Meta) meta.Meta {
+	if lang == nil {
+		return siteMeta
+	}
+	return sb.languageMeta[lang.Code]
+}
+
+// languageGroup is the pages of one language
+type languageGroup struct {
+	lang    *domain.Language
+	sources []*pageSource
+}
+
+// groupByLanguage splits pages by language in language order, leaving out
+// languages without pages. Sites without languages have a single group
+// without a language.
+func (sb *SiteBuilder) groupByLanguage(sources []*pageSource) []languageGroup {
+	if len(sb.languages) == 0 {
+		return []languageGroup{{sources: sources}}
+	}
+	var groups []languageGroup
+	for _, lang := range sb.languages {
+		group := languageGroup{lang: lang}
+		for _, src := range sources {
+			if src.page.Language == lang {
+				group.sources = append(group.sources, src)
+			}
+		}
+		if len(group.sources) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// linkTranslations gives every page its versions in the other languages, the
+// pages at the same path in the other content trees, and lists all versions
+// as hreflang alternates in its meta. A page is rebuilt when a version is
+// added, removed or changed.
+func (sb *SiteBuilder) linkTranslations(sources []*pageSource) {
+	if len(sb.languages) == 0 {
+		return
+	}
+	versions := make(map[string][]*pageSource)
+	for _, src := range sources {
+		rel := languageRel(src.page.Language, src.treePath)
+		versions[rel] = append(versions[rel], src)
+	}
+
+	baseURL := sb.baseURL()
+	order := make(map[*domain.Language]int, len(sb.languages))
+	for i, lang := range sb.languages {
+		order[lang] = i
+	}
+	for _, group := range versions {
+		sort.SliceStable(group, func(i, j int) bool {
+			return order[group[i].page.Language] < order[group[j].page.Language]
+		})
+		if len(group) < 2 {
+			continue
+		}
+
+		var alternates []meta.Alternate
+		var deps []byte
+		for _, src := range group {
+			alternates = append(alternates, meta.Alternate{Lang: src.page.Language.Code, URL: baseURL + src.page.URL})
+			deps = append(deps, src.page.Path...)
+			deps = append(deps, src.entry.SourceHash...)
+		}
+		for _, src := range group {
+			if src.page.Language.Default {
+				alternates = append(alternates, meta.Alternate{Lang: "x-default", URL: baseURL + src.page.URL})
+			}
+		}
+
+		for _, src := range group {
+			src.page.Translations = nil
+			for _, other := range group {
+				if other != src {
+					src.page.Translations = append(src.page.Translations, other.page)
+				}
+			}
+			src.page.Meta.Alternates = alternates
+			src.entry.DepsHash = hashBytes([]byte(src.entry.DepsHash + "\n" + hashBytes(deps)))
+		}
+	}
+}
+
+// languageOutputs returns the path of the home page writeLanguageRedirect
+// writes on multilingual sites, so that no page can claim it
+func (sb *SiteBuilder) languageOutputs() map[string]string {
+	if sb.defaultLanguage() == nil {
+		return nil
+	}
+	return map[string]string{domain.IndexFile: "the language redirect"}
+}
+
+// writeLanguageRedirect writes a home page that redirects to the home page of
+// the default language, since every page of a multilingual site is written
+// under its language
+func (sb *SiteBuilder) writeLanguageRedirect() error {
+	lang := sb.defaultLanguage()
+	if lang == nil {
+		return nil
+	}
+	return sb.writeFile(filepath.Join(sb.outDir, domain.IndexFile), redirectPage(lang.URL))
+}
+
+// redirectPage returns an HTML page that redirects to url
+func redirectPage(url string) []byte {
+	escaped := template.HTMLEscapeString(url)
+	return []byte(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Redirecting to ` + escaped + `</title>
+<link rel="canonical" href="` + escaped + `">
+<meta http-equiv="refresh" content="0; url=` + escaped + `">
+</head>
+<body>
+<p>Redirecting to <a href="` + escaped + `">` + escaped + `</a>.</p>
+</body>
+</html>
+`)
+}
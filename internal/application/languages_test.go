@@ -0,0 +1,231 @@
+package application
+
+import (
+	"html/template"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+func multilingualSite() *domain.Site {
+	return &domain.Site{
+		PagesDir: "content",
+		DistDir:  "dist",
+		I18nDir:  "i18n",
+		Config: map[string]interface{}{
+			"base_url": template.HTML("https://example.com"),
+			"languages": map[string]interface{}{
+				"en": map[string]interface{}{"name": template.HTML("English"), "weight": 1},
+				"de": map[string]interface{}{
+					"name":   template.HTML("Deutsch"),
+					"weight": 2,
+					"meta":   map[string]interface{}{"description": template.HTML("Eine Seite")},
+				},
+				"ja": map[string]interface{}{"name": template.HTML("日本語"), "weight": 3},
+			},
+		},
+	}
+}
+
+func TestLoadLanguages(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.files["i18n/en.yaml"] = []byte("read_more: Read more\nnav:\n  home: Home\nposts: \"%d posts\"\n")
+	fs.files["i18n/de.yaml"] = []byte("read_more: Weiterlesen\nnav:\n  home: Startseite\n")
+	builder := newTestBuilder(multilingualSite(), fs, NewMockTemplateRenderer())
+
+	if err := builder.loadLanguages(); err != nil {
+		t.Fatalf("loadLanguages failed: %v", err)
+	}
+	if len(builder.languages) != 3 || builder.languages[0].Code != "en" || builder.languages[2].Code != "ja" {
+		t.Fatalf("Expected languages ordered by weight, got %+v", builder.languages)
+	}
+	en, de, ja := builder.languages[0], builder.languages[1], builder.languages[2]
+	if !en.Default || de.Default || de.URL != "/de/" || de.Name != "Deutsch" {
+		t.Errorf("Unexpected languages: %+v, %+v", en, de)
+	}
+
+	tests := []struct {
+		lang     *domain.Language
+		key      string
+		args     []interface{}
+		expected string
+	}{
+		{de, "read_more", nil, "Weiterlesen"},
+		{de, "nav.home", nil, "Startseite"},
+		{de, "posts", []interface{}{3}, "3 posts"},
+		{ja, "read_more", nil, "Read more"},
+		{ja, "missing", nil, "missing"},
+		{nil, "read_more", nil, "read_more"},
+	}
+	for _, tt := range tests {
+		page := domain.Page{Language: tt.lang}
+		if got := page.T(tt.key, tt.args...); got != tt.expected {
+			t.Errorf("T(%q) in %v = %q, expected %q", tt.key, tt.lang, got, tt.expected)
+		}
+	}
+
+	if builder.languageMeta["de"].Description != "Eine Seite" {
+		t.Errorf("Expected German site meta, got %+v", builder.languageMeta["de"])
+	}
+}
+
+func TestLoadLanguages_DefaultLanguage(t *testing.T) {
+	site := multilingualSite()
+	site.Config["default_language"] = template.HTML("de")
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+	if err := builder.loadLanguages(); err != nil {
+		t.Fatalf("loadLanguages failed: %v", err)
+	}
+	if def := builder.defaultLanguage(); def == nil || def.Code != "de" {
+		t.Errorf("Expected de as default language, got %+v", def)
+	}
+
+	site.Config["default_language"] = template.HTML("fr")
+	if err := builder.loadLanguages(); err == nil || !strings.Contains(err.Error(), `"fr" is not declared`) {
+		t.Errorf("Expected an undeclared default language error, got %v", err)
+	}
+}
+
+func TestPageLanguage(t *testing.T) {
+	builder := newTestBuilder(multilingualSite(), NewMockFileSystem(), NewMockTemplateRenderer())
+	if err := builder.loadLanguages(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rel, lang, langRel string
+	}{
+		{"index.html", "en", "index.html"},
+		{"de/index.html", "de", "index.html"},
+		{"de/blog/post.html", "de", "blog/post.html"},
+		{"about/index.ja.html", "ja", "about/index.html"},
+		{"blog/post.de.html", "de", "blog/post.html"},
+		{"fr/index.html", "en", "fr/index.html"},
+		{"v1.2/index.html", "en", "v1.2/index.html"},
+	}
+	for _, tt := range tests {
+		lang, rel := builder.pageLanguage(filepath.FromSlash(tt.rel))
+		if lang.Code != tt.lang || filepath.ToSlash(rel) != tt.langRel {
+			t.Errorf("pageLanguage(%q) = %s, %q, expected %s, %q", tt.rel, lang.Code, rel, tt.lang, tt.langRel)
+		}
+	}
+}
+
+func TestBuildPages_Multilingual(t *testing.T) {
+	site := multilingualSite()
+	site.Config["taxonomies"] = map[string]interface{}{"tags": template.HTML("tag")}
+	fs := NewMockFileSystem()
+	fs.files["i18n/de.yaml"] = []byte("read_more: Weiterlesen\n")
+	fs.files["content/index.html"] = []byte("<p>Home</p>")
+	fs.files["content/about/index.html"] = []byte("---\ntags: [go]\n---\n<p>About</p>")
+	fs.files["content/about/index.de.html"] = []byte("---\ntitle: Über uns\n---\n<p>Über</p>")
+	fs.files["content/de/index.html"] = []byte(`<p>{{.T "read_more"}}</p>`)
+	renderer := NewMockTemplateRenderer()
+	builder := newTestBuilder(site, fs, renderer)
+	if err := builder.loadLanguages(); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+	for _, layout := range []string{domain.TaxonomyLayout, domain.TermLayout} {
+		template.Must(tmpl.New(domain.LayoutsDir + "/" + layout + domain.PageExt).Parse(`{{.Title}}`))
+	}
+
+	if err := builder.buildPages(tmpl, meta.Meta{}); err != nil {
+		t.Fatalf("buildPages failed: %v", err)
+	}
+	if err := builder.writeLanguageRedirect(); err != nil {
+		t.Fatalf("writeLanguageRedirect failed: %v", err)
+	}
+
+	for _, path := range []string{
+		"dist/index.html",
+		"dist/en/index.html",
+		"dist/en/about/index.html",
+		"dist/en/tags/go/index.html",
+		"dist/de/index.html",
+		"dist/de/about/index.html",
+		"dist/de/tags/index.html",
+	} {
+		if fs.written[path] == nil {
+			t.Errorf("Expected %s to be written", path)
+		}
+	}
+	if fs.written["dist/ja/tags/index.html"] != nil {
+		t.Error("Languages without pages should get no taxonomy pages")
+	}
+	if got := fs.written["dist/de/index.html"].String(); !strings.Contains(got, "<p>Weiterlesen</p>") {
+		t.Errorf("Expected translated German home page, got %q", got)
+	}
+	if got := fs.written["dist/index.html"].String(); !strings.Contains(got, `url=/en/`) {
+		t.Errorf("Expected a redirect to /en/, got %q", got)
+	}
+}
+
+func TestBuildPages_LanguageRedirectClaimed(t *testing.T) {
+	site := multilingualSite()
+	fs := NewMockFileSystem()
+	fs.files["content/index.html"] = []byte("<p>Home</p>")
+	fs.files["content/start.html"] = []byte("---\nurl: /\n---\n<p>Start</p>")
+	renderer := NewMockTemplateRenderer()
+	builder := newTestBuilder(site, fs, renderer)
+	if err := builder.loadLanguages(); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	err := builder.buildPages(tmpl, meta.Meta{})
+	expected := "/ is claimed by more than one page: content/start.html, the language redirect"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("buildPages error = %v, expected %q", err, expected)
+	}
+}
+
+func TestLinkTranslations(t *testing.T) {
+	builder := newTestBuilder(multilingualSite(), NewMockFileSystem(), NewMockTemplateRenderer())
+	if err := builder.loadLanguages(); err != nil {
+		t.Fatal(err)
+	}
+	en, de, ja := builder.languages[0], builder.languages[1], builder.languages[2]
+	source := func(lang *domain.Language, rel string) *pageSource {
+		src := testSource(languagePath(lang, rel), nil)
+		src.page.Language = lang
+		return src
+	}
+
+	aboutJa := source(ja, "about/index.html")
+	aboutEn := source(en, "about/index.html")
+	aboutDe := source(de, "about/index.html")
+	onlyEn := source(en, "blog/post.html")
+	builder.linkTranslations([]*pageSource{aboutJa, aboutEn, aboutDe, onlyEn})
+
+	if got := pagePaths(aboutEn.page.Translations); len(got) != 2 || got[0] != "de/about/index.html" || got[1] != "ja/about/index.html" {
+		t.Errorf("English translations = %v", got)
+	}
+	if got := pagePaths(aboutJa.page.Translations); len(got) != 2 || got[0] != "en/about/index.html" {
+		t.Errorf("Japanese translations = %v", got)
+	}
+	expected := []meta.Alternate{
+		{Lang: "en", URL: "https://example.com/en/about/"},
+		{Lang: "de", URL: "https://example.com/de/about/"},
+		{Lang: "ja", URL: "https://example.com/ja/about/"},
+		{Lang: "x-default", URL: "https://example.com/en/about/"},
+	}
+	alternates := aboutDe.page.Meta.Alternates
+	if len(alternates) != len(expected) {
+		t.Fatalf("Alternates = %+v", alternates)
+	}
+	for i := range expected {
+		if alternates[i] != expected[i] {
+			t.Errorf("Alternate %d = %+v, expected %+v", i, alternates[i], expected[i])
+		}
+	}
+	if onlyEn.page.Translations != nil || onlyEn.page.Meta.Alternates != nil {
+		t.Error("Pages without translations should have no alternates")
+	}
+	if aboutEn.entry.DepsHash == "" || onlyEn.entry.DepsHash != "" {
+		t.Error("Expected translated pages to depend on their translations")
+	}
+}
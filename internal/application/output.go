@@ -0,0 +1,62 @@
+package application
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+// outputStep transforms the rendered HTML of a page before it is written
+type outputStep func(html []byte) ([]byte, error)
+
+// outputWriter buffers the rendered HTML of a page and writes it to the page's
+// file through the post-render steps when it is closed
+type outputWriter struct {
+	bytes.Buffer
+	file  io.WriteCloser
+	steps []outputStep
+}
+
+// Close applies the post-render steps to the buffered HTML, writes the result
+// and closes the file
+func (w *outputWriter) Close() error {
+	html := w.Bytes()
+	for _, step := range w.steps {
+		var err error
+		if html, err = step(html); err != nil {
+			return errors.Join(err, w.file.Close())
+		}
+	}
+	if _, err := w.file.Write(html); err != nil {
+		return errors.Join(err, w.file.Close())
+	}
+	return w.file.Close()
+}
+
+// createOutput creates the output file of a page. Templates execute into the
+// returned writer, which applies the post-render steps the page needs, such as
+// the draft marker or HTML minification, and writes the file when it is
+// closed. Without steps the file is returned as is.
+func (sb *SiteBuilder) createOutput(dst string, page *domain.Page) (io.WriteCloser, error) {
+	var steps []outputStep
+	if page.Draft {
+		// Drafts are only rendered by stw serve, marked as such
+		steps = append(steps, func(html []byte) ([]byte, error) {
+			return markDraft(html), nil
+		})
+	}
+	if sb.site.MinifyHTML && filepath.Ext(dst) == domain.PageExt {
+		steps = append(steps, func(html []byte) ([]byte, error) {
+			return sb.minifier.Minify(domain.PageExt, html)
+		})
+	}
+
+	f, err := sb.fs.Create(dst)
+	if err != nil || len(steps) == 0 {
+		return f, err
+	}
+	return &outputWriter{file: f, steps: steps}, nil
+}
@@ -0,0 +1,57 @@
+package application
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+func TestRenderPage_MinifyHTML(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.files["content/index.html"] = []byte("<div>\n  <!-- Intro -->\n  <p>Home  page</p>\n</div>\n<pre>  kept\n</pre>\n")
+	fs.files["content/feed.html"] = []byte("---\nurl: /feed.xml\n---\n<feed>\n  <title>Kept</title>\n</feed>\n")
+	site := &domain.Site{PagesDir: "content", DistDir: "dist", MinifyHTML: true}
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+	tmpl, _ := NewMockTemplateRenderer().ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	if err := builder.buildPages(tmpl, meta.Meta{}); err != nil {
+		t.Fatalf("buildPages failed: %v", err)
+	}
+	if got := fs.written["dist/index.html"].String(); got != "Home<div><p>Home page</div><pre>  kept\n</pre>" {
+		t.Errorf("Expected the page to be minified, got %q", got)
+	}
+	if got := fs.written["dist/feed.xml"].String(); got != "Feed<feed>\n  <title>Kept</title>\n</feed>\n" {
+		t.Errorf("Expected output other than HTML to be written as rendered, got %q", got)
+	}
+}
+
+func TestRenderPage_MinifyHTMLDraft(t *testing.T) {
+	builder, fs := draftTestBuilder(true)
+	builder.site.MinifyHTML = true
+	tmpl, _ := NewMockTemplateRenderer().ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	if err := builder.buildPages(tmpl, meta.Meta{}); err != nil {
+		t.Fatalf("buildPages failed: %v", err)
+	}
+	if got := fs.written["dist/blog/draft.html"].String(); got != draftMarker+"Draft<p>Work in progress</p>" {
+		t.Errorf("Expected the draft to be marked and minified, got %q", got)
+	}
+}
+
+func TestHashInputs_MinifyHTML(t *testing.T) {
+	builder := newTestBuilder(&domain.Site{DistDir: "dist"}, NewMockFileSystem(), NewMockTemplateRenderer())
+	if err := builder.hashInputs(nil); err != nil {
+		t.Fatal(err)
+	}
+	plain := builder.configHash
+
+	builder.site.MinifyHTML = true
+	if err := builder.hashInputs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if builder.configHash == plain {
+		t.Error("Expected HTML minification to change the config hash so pages are rebuilt")
+	}
+}
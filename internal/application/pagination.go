@@ -0,0 +1,112 @@
+package application
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+// paginate splits the listings of list and term pages into pages of the
+// configured size. The first page keeps the listing's own path and later
+// pages are written under page/<n>/ in its directory, such as /blog/page/2/,
+// or /page/2/ for a listing served at /blog.html. Every listing page receives
+// a pager; the generated pages are returned.
+func (sb *SiteBuilder) paginate(sources []*pageSource) ([]*pageSource, error) {
+	var generated []*pageSource
+	for _, src := range sources {
+		if src.page.Pages == nil {
+			continue
+		}
+		size, err := sb.pageSize(src)
+		if err != nil {
+			return nil, err
+		}
+		if size < 1 {
+			continue
+		}
+
+		items := src.page.Pages
+		total := (len(items) + size - 1) / size
+		if total < 1 {
+			total = 1
+		}
+		dir := filepath.Dir(src.page.Path)
+		first := src.page.URL
+		base := dirURL(src.page)
+		pagerURL := func(n int) string {
+			if n == 1 {
+				return first
+			}
+			return base + domain.PagerDir + "/" + strconv.Itoa(n) + "/"
+		}
+
+		for n := 1; n <= total; n++ {
+			start := (n - 1) * size
+			end := start + size
+			if end > len(items) {
+				end = len(items)
+			}
+			pager := &domain.Pager{
+				PageNumber: n,
+				TotalPages: total,
+				TotalItems: len(items),
+				PageSize:   size,
+				Pages:      items[start:end],
+				First:      first,
+				Last:       pagerURL(total),
+			}
+			if n > 1 {
+				pager.Prev = pagerURL(n - 1)
+			}
+			if n < total {
+				pager.Next = pagerURL(n + 1)
+			}
+
+			if n == 1 {
+				src.page.Pager = pager
+				continue
+			}
+			page := *src.page
+			page.Path = filepath.Join(dir, domain.PagerDir, strconv.Itoa(n), domain.IndexFile)
+			page.URL = pagerURL(n)
+			page.Pager = pager
+			paged := *src
+			paged.treePath = filepath.Join(filepath.Dir(src.treePath), domain.PagerDir, strconv.Itoa(n), domain.IndexFile)
+			paged.page = &page
+			generated = append(generated, &paged)
+		}
+	}
+	return generated, nil
+}
+
+// pageSize returns the number of items per page of a listing: the paginate
+// front matter key, or else the pagination config entry of the listing's
+// section directory, such as blog, or of its taxonomy on term pages.
+// Listings are not paginated when it is 0.
+func (sb *SiteBuilder) pageSize(src *pageSource) (int, error) {
+	page := src.page
+	size, err := numberParam(page.Params, "paginate")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", src.path, err)
+	}
+	if size > 0 {
+		return size, nil
+	}
+	sizes, _ := sb.site.Config["pagination"].(map[string]interface{})
+	key := filepath.ToSlash(filepath.Dir(languageRel(page.Language, src.treePath)))
+	if page.Term != nil && page.Taxonomy != nil {
+		key = page.Taxonomy.Name
+	}
+	if size, err = numberParam(sizes, key); err != nil {
+		return 0, fmt.Errorf("pagination: %w", err)
+	}
+	return size, nil
+}
+
+// dirURL returns the URL of the directory a page is written to, such as
+// /blog/ for a page served at /blog/ and / for one served at /blog.html
+func dirURL(page *domain.Page) string {
+	return pageURL(filepath.Join(filepath.Dir(page.Path), domain.IndexFile))
+}
@@ -0,0 +1,127 @@
+package application
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+func TestPaginate(t *testing.T) {
+	site := &domain.Site{PagesDir: "pages", Config: map[string]interface{}{}}
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	blog := testSource("blog/index.html", map[string]interface{}{"paginate": 2})
+	for i := 1; i <= 5; i++ {
+		blog.page.Pages = append(blog.page.Pages, testSource(fmt.Sprintf("blog/post%d.html", i), nil).page)
+	}
+	about := testSource("about.html", nil)
+
+	generated, err := builder.paginate([]*pageSource{blog, about})
+	if err != nil {
+		t.Fatalf("paginate failed: %v", err)
+	}
+
+	if len(generated) != 2 {
+		t.Fatalf("Expected 2 generated pages, got %d", len(generated))
+	}
+	if generated[0].page.Path != "blog/page/2/index.html" || generated[1].page.URL != "/blog/page/3/" {
+		t.Errorf("Unexpected pager pages: %s, %s", generated[0].page.Path, generated[1].page.URL)
+	}
+	if generated[0].path != blog.path || generated[0].page.Params["paginate"] != 2 {
+		t.Error("Pager pages should render from the listing's source")
+	}
+
+	first := blog.page.Pager
+	if first == nil || first.PageNumber != 1 || first.TotalPages != 3 || first.TotalItems != 5 || first.PageSize != 2 {
+		t.Fatalf("Unexpected first pager: %+v", first)
+	}
+	if first.Prev != "" || first.Next != "/blog/page/2/" || first.First != "/blog/" || first.Last != "/blog/page/3/" {
+		t.Errorf("Unexpected first pager URLs: %+v", first)
+	}
+	if got := pagePaths(first.Pages); len(got) != 2 || got[0] != "blog/post1.html" {
+		t.Errorf("First pager pages = %v", got)
+	}
+
+	second := generated[0].page.Pager
+	if second.PageNumber != 2 || second.Prev != "/blog/" || second.Next != "/blog/page/3/" {
+		t.Errorf("Unexpected second pager: %+v", second)
+	}
+	last := generated[1].page.Pager
+	if got := pagePaths(last.Pages); len(got) != 1 || got[0] != "blog/post5.html" || last.Next != "" {
+		t.Errorf("Unexpected last pager: %+v", last)
+	}
+
+	if about.page.Pager != nil {
+		t.Error("Regular pages should not be paginated")
+	}
+}
+
+func TestPaginate_FileURL(t *testing.T) {
+	site := &domain.Site{PagesDir: "pages", Config: map[string]interface{}{}}
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	// A listing moved to a file URL, as with url: /news/blog.html
+	blog := testSource("blog/index.html", map[string]interface{}{"paginate": 1})
+	blog.page.Path = "news/blog.html"
+	blog.page.URL = "/news/blog.html"
+	for i := 1; i <= 2; i++ {
+		blog.page.Pages = append(blog.page.Pages, testSource(fmt.Sprintf("blog/post%d.html", i), nil).page)
+	}
+
+	generated, err := builder.paginate([]*pageSource{blog})
+	if err != nil {
+		t.Fatalf("paginate failed: %v", err)
+	}
+
+	if len(generated) != 1 || generated[0].page.Path != "news/page/2/index.html" {
+		t.Fatalf("Unexpected pager pages: %v", generated)
+	}
+	if got := blog.page.Pager.Next; got != "/news/page/2/" || generated[0].page.URL != got {
+		t.Errorf("Expected the next URL to match the generated page, got %q and %q", got, generated[0].page.URL)
+	}
+	if second := generated[0].page.Pager; second.Prev != "/news/blog.html" || second.First != "/news/blog.html" {
+		t.Errorf("Unexpected second pager: %+v", second)
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	site := &domain.Site{
+		PagesDir: "pages",
+		Config: map[string]interface{}{
+			"pagination": map[string]interface{}{"blog": 10, "docs/guides": template.HTML("5"), "tags": 20},
+		},
+	}
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	tags := &domain.Taxonomy{Name: "tags"}
+	term := testSource("tags/go/index.html", nil)
+	term.page.Taxonomy = tags
+	term.page.Term = &domain.Term{Name: "go"}
+
+	tests := []struct {
+		src      *pageSource
+		expected int
+	}{
+		{testSource("blog/index.html", nil), 10},
+		{testSource("blog/index.html", map[string]interface{}{"paginate": 3}), 3},
+		{testSource("docs/guides/index.html", nil), 5},
+		{testSource("docs/index.html", nil), 0},
+		{term, 20},
+	}
+	for _, tt := range tests {
+		if got, err := builder.pageSize(tt.src); err != nil || got != tt.expected {
+			t.Errorf("pageSize(%s) = %d, %v, expected %d", tt.src.page.Path, got, err, tt.expected)
+		}
+	}
+
+	if _, err := builder.pageSize(testSource("blog/index.html", map[string]interface{}{"paginate": "ten"})); err == nil || !strings.Contains(err.Error(), `invalid paginate "ten"`) {
+		t.Errorf("Expected an error for a paginate value that is not a number, got %v", err)
+	}
+	site.Config["pagination"] = map[string]interface{}{"blog": template.HTML("ten")}
+	if _, err := builder.pageSize(testSource("blog/index.html", nil)); err == nil || !strings.Contains(err.Error(), `pagination: invalid blog "ten"`) {
+		t.Errorf("Expected an error for a pagination size that is not a number, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package application
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateFormats are the layouts accepted for dates given as strings in front matter
+var dateFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// stringParam returns the front matter value for key if it is a string
+func stringParam(params map[string]interface{}, key string) string {
+	s, _ := params[key].(string)
+	return s
+}
+
+// intParam returns the front matter value for key as an int, or 0 if it is
+// missing or not a number
+func intParam(params map[string]interface{}, key string) int {
+	n, _ := numberParam(params, key)
+	return n
+}
+
+// numberParam returns the front matter or config value for key as an int, or
+// 0 if it is missing. Numbers may also be given as strings, such as "302";
+// other values are an error.
+func numberParam(params map[string]interface{}, key string) (int, error) {
+	switch v := params[key].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	case string, template.HTML:
+		if n, err := strconv.Atoi(strings.TrimSpace(configString(v))); err == nil {
+			return n, nil
+		}
+		return 0, fmt.Errorf("invalid %s %q, expected a number", key, configString(v))
+	}
+	return 0, fmt.Errorf("invalid %s %v, expected a number", key, params[key])
+}
+
+// stringList returns a front matter or config value given either as a list or
+// as a single value as a list of strings, leaving out empty values
+func stringList(value interface{}) []string {
+	var values []interface{}
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		values = v
+	default:
+		values = []interface{}{v}
+	}
+
+	var list []string
+	for _, v := range values {
+		if s := strings.TrimSpace(fmt.Sprint(v)); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// timeParam returns the front matter value for key as a time, or the zero
+// time if it is missing
+func timeParam(params map[string]interface{}, key string) (time.Time, error) {
+	switch v := params[key].(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	case string:
+		return parseDate(v)
+	}
+	return time.Time{}, fmt.Errorf("invalid %s: %v", key, params[key])
+}
+
+// parseDate parses a date in one of dateFormats
+func parseDate(s string) (time.Time, error) {
+	for _, format := range dateFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", s)
+}
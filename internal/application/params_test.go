@@ -0,0 +1,78 @@
+package application
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIntParam(t *testing.T) {
+	params := map[string]interface{}{"a": 3, "b": 4.0, "c": "5", "d": "x", "e": template.HTML("6")}
+	for key, expected := range map[string]int{"a": 3, "b": 4, "c": 5, "d": 0, "e": 6, "missing": 0} {
+		if got := intParam(params, key); got != expected {
+			t.Errorf("intParam(%q) = %d, expected %d", key, got, expected)
+		}
+	}
+}
+
+func TestNumberParam(t *testing.T) {
+	params := map[string]interface{}{"a": 3, "b": template.HTML(" 302 "), "c": template.HTML("ten"), "d": 2.5, "e": true}
+	for key, expected := range map[string]int{"a": 3, "b": 302, "missing": 0} {
+		if got, err := numberParam(params, key); err != nil || got != expected {
+			t.Errorf("numberParam(%q) = %d, %v, expected %d", key, got, err, expected)
+		}
+	}
+	for key, expected := range map[string]string{
+		"c": `invalid c "ten", expected a number`,
+		"d": "invalid d 2.5, expected a number",
+		"e": "invalid e true, expected a number",
+	} {
+		if _, err := numberParam(params, key); err == nil || err.Error() != expected {
+			t.Errorf("numberParam(%q) error = %v, expected %q", key, err, expected)
+		}
+	}
+}
+
+func TestTimeParam(t *testing.T) {
+	expected := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	params := map[string]interface{}{
+		"time":   expected,
+		"string": "2024-05-06",
+		"rfc":    "2024-05-06T00:00:00Z",
+		"bad":    "May 6th",
+		"number": 42,
+	}
+	for _, key := range []string{"time", "string", "rfc"} {
+		got, err := timeParam(params, key)
+		if err != nil || !got.Equal(expected) {
+			t.Errorf("timeParam(%q) = %v, %v", key, got, err)
+		}
+	}
+	for _, key := range []string{"bad", "number"} {
+		if _, err := timeParam(params, key); err == nil {
+			t.Errorf("Expected error for %q", key)
+		}
+	}
+	if got, err := timeParam(params, "missing"); err != nil || !got.IsZero() {
+		t.Errorf("Expected zero time for missing key, got %v, %v", got, err)
+	}
+}
+
+func TestStringList(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected []string
+	}{
+		{nil, nil},
+		{"go", []string{"go"}},
+		{template.HTML("/admin/"), []string{"/admin/"}},
+		{[]interface{}{"a", " ", 2024}, []string{"a", "2024"}},
+	}
+	for _, tt := range tests {
+		got := stringList(tt.value)
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") || len(got) != len(tt.expected) {
+			t.Errorf("stringList(%v) = %v, expected %v", tt.value, got, tt.expected)
+		}
+	}
+}
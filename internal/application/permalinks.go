@@ -0,0 +1,162 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+// permalinkToken matches a placeholder in a permalink pattern, such as :year
+var permalinkToken = regexp.MustCompile(`:[a-z]+`)
+
+// dateTokens are the date placeholders of permalink patterns and their layouts
+var dateTokens = map[string]string{":year": "2006", ":month": "01", ":day": "02"}
+
+// applyPermalink moves a page from its place in the pages tree to its public
+// URL. The url front matter key sets the full URL path; otherwise the
+// permalinks config pattern of the page's section, such as
+//
+//	permalinks:
+//	  blog: /blog/:year/:month/:slug/
+//
+// places every page of the section except its list page, under the page's
+// language on multilingual sites. The slug front matter key renames the page
+// within its directory, or sets :slug in the pattern. Record pages take their
+// slug from their record, not the front matter of their template.
+func (sb *SiteBuilder) applyPermalink(src *pageSource) error {
+	page := src.page
+	var url, slug string
+	if page.Record == nil {
+		url = stringParam(page.Params, "url")
+		slug = stringParam(page.Params, "slug")
+		if slug != "" && (strings.ContainsAny(slug, `/\`) || slug == "." || slug == "..") {
+			return fmt.Errorf("invalid slug %q", slug)
+		}
+	}
+
+	rel := src.treePath
+	langRel := languageRel(page.Language, rel)
+	pattern := configString(sb.permalinks()[page.Section])
+	switch {
+	case url != "":
+		var err error
+		if rel, err = urlPath(url); err != nil {
+			return err
+		}
+	case pattern != "" && langRel != path.Join(page.Section, domain.IndexFile):
+		expanded, err := expandPermalink(pattern, page, treeSlug(langRel, slug))
+		if err != nil {
+			return err
+		}
+		if rel, err = urlPath(expanded); err != nil {
+			return fmt.Errorf("permalink %q: %w", pattern, err)
+		}
+		rel = languagePath(page.Language, rel)
+	case slug != "":
+		if filepath.Base(rel) == domain.IndexFile {
+			rel = filepath.Join(filepath.Dir(filepath.Dir(rel)), slug, domain.IndexFile)
+		} else {
+			rel = filepath.Join(filepath.Dir(rel), slug+domain.PageExt)
+		}
+	default:
+		return nil
+	}
+
+	page.Path = rel
+	page.URL = pageURL(rel)
+	return nil
+}
+
+// permalinks returns the permalinks config block, patterns by section
+func (sb *SiteBuilder) permalinks() map[string]interface{} {
+	patterns, _ := sb.site.Config["permalinks"].(map[string]interface{})
+	return patterns
+}
+
+// treeSlug returns the slug of a page: the slug front matter key, or else the
+// name of its file, or of its directory for index pages
+func treeSlug(rel, slug string) string {
+	if slug != "" {
+		return slug
+	}
+	if filepath.Base(rel) == domain.IndexFile {
+		return filepath.Base(filepath.Dir(rel))
+	}
+	return strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+}
+
+// expandPermalink replaces the placeholders of a permalink pattern with the
+// values of a page: :year, :month and :day of its date, :slug, :title (the
+// title the page is listed with) and :section
+func expandPermalink(pattern string, page *domain.Page, slug string) (string, error) {
+	var err error
+	expanded := permalinkToken.ReplaceAllStringFunc(pattern, func(token string) string {
+		switch token {
+		case ":year", ":month", ":day":
+			if page.Date.IsZero() {
+				err = fmt.Errorf("permalink %q uses %s but the page has no date", pattern, token)
+				return ""
+			}
+			return page.Date.Format(dateTokens[token])
+		case ":slug":
+			return slug
+		case ":title":
+			return urlize(itemTitle(page))
+		case ":section":
+			return page.Section
+		}
+		err = fmt.Errorf("permalink %q has unknown placeholder %s", pattern, token)
+		return ""
+	})
+	return expanded, err
+}
+
+// urlPath returns the output path of a URL path relative to the output
+// directory. URLs ending in a slash or without an extension are written as an
+// index page, such as /about/ to about/index.html.
+func urlPath(url string) (string, error) {
+	clean := path.Clean("/" + url)
+	for _, segment := range strings.Split(url, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("invalid url %q", url)
+		}
+	}
+	if clean == "/" {
+		return domain.IndexFile, nil
+	}
+	if strings.HasSuffix(url, "/") || path.Ext(clean) == "" {
+		return filepath.FromSlash(strings.TrimPrefix(clean, "/") + "/" + domain.IndexFile), nil
+	}
+	return filepath.FromSlash(strings.TrimPrefix(clean, "/")), nil
+}
+
+// checkOutputs reports pages that claim the same output URL, or the path of an
+// output the build writes besides the pages, naming every source that claims
+// it. outputs maps such paths to what writes them.
+func checkOutputs(sources []*pageSource, outputs map[string]string) error {
+	claims := make(map[string][]string)
+	for path, writer := range outputs {
+		claims[path] = []string{writer}
+	}
+	for _, src := range sources {
+		claims[src.page.Path] = append(claims[src.page.Path], src.path)
+	}
+
+	var errs []error
+	for _, src := range sources {
+		claimed := claims[src.page.Path]
+		if len(claimed) < 2 {
+			continue
+		}
+		delete(claims, src.page.Path)
+		sort.Strings(claimed)
+		errs = append(errs, fmt.Errorf("%s is claimed by more than one page: %s", src.page.URL, strings.Join(claimed, ", ")))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,131 @@
+package application
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+func TestApplyPermalink(t *testing.T) {
+	site := &domain.Site{
+		Config: map[string]interface{}{
+			"permalinks": map[string]interface{}{
+				"blog": "/blog/:year/:month/:slug/",
+				"docs": "/guide/:title.html",
+			},
+		},
+	}
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	tests := []struct {
+		rel    string
+		params map[string]interface{}
+		path   string
+		url    string
+	}{
+		{"about/index.html", nil, "about/index.html", "/about/"},
+		{"about/index.html", map[string]interface{}{"slug": "team"}, "team/index.html", "/team/"},
+		{"news/old.html", map[string]interface{}{"slug": "new"}, "news/new.html", "/news/new.html"},
+		{"news/old.html", map[string]interface{}{"url": "/press/"}, "press/index.html", "/press/"},
+		{"news/old.html", map[string]interface{}{"url": "feed.json"}, "feed.json", "/feed.json"},
+		{"news/old.html", map[string]interface{}{"url": "/"}, "index.html", "/"},
+		{"blog/index.html", nil, "blog/index.html", "/blog/"},
+		{"blog/hello.html", map[string]interface{}{"date": "2024-05-03"}, "blog/2024/05/hello/index.html", "/blog/2024/05/hello/"},
+		{"blog/trip/index.html", map[string]interface{}{"date": "2024-05-03", "slug": "alps"}, "blog/2024/05/alps/index.html", "/blog/2024/05/alps/"},
+		{"blog/hello.html", map[string]interface{}{"date": "2024-05-03", "url": "/hi/"}, "hi/index.html", "/hi/"},
+		{"docs/setup.html", map[string]interface{}{"title": "Getting Started"}, "guide/getting-started.html", "/guide/getting-started.html"},
+		{"docs/setup.html", nil, "guide/setup.html", "/guide/setup.html"},
+	}
+	for _, tt := range tests {
+		src := testSource(tt.rel, tt.params)
+		if err := builder.applyPermalink(src); err != nil {
+			t.Errorf("applyPermalink(%s, %v) failed: %v", tt.rel, tt.params, err)
+			continue
+		}
+		if src.page.Path != tt.path || src.page.URL != tt.url {
+			t.Errorf("applyPermalink(%s, %v) = %s, %s, expected %s, %s", tt.rel, tt.params, src.page.Path, src.page.URL, tt.path, tt.url)
+		}
+		if src.treePath != tt.rel {
+			t.Errorf("applyPermalink(%s) changed the tree path to %s", tt.rel, src.treePath)
+		}
+	}
+}
+
+func TestApplyPermalink_Errors(t *testing.T) {
+	site := &domain.Site{
+		Config: map[string]interface{}{
+			"permalinks": map[string]interface{}{"blog": "/blog/:year/:slug/", "docs": "/docs/:name/"},
+		},
+	}
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	tests := []struct {
+		rel      string
+		params   map[string]interface{}
+		expected string
+	}{
+		{"blog/hello.html", nil, "uses :year but the page has no date"},
+		{"docs/setup.html", nil, "unknown placeholder :name"},
+		{"about.html", map[string]interface{}{"slug": "a/b"}, `invalid slug "a/b"`},
+		{"about.html", map[string]interface{}{"url": "/../outside/"}, `invalid url "/../outside/"`},
+	}
+	for _, tt := range tests {
+		err := builder.applyPermalink(testSource(tt.rel, tt.params))
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("applyPermalink(%s, %v) error = %v, expected %q", tt.rel, tt.params, err, tt.expected)
+		}
+	}
+}
+
+func TestBuildPages_Permalinks(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.files["content/index.html"] = []byte("<p>Home</p>")
+	fs.files["content/blog/index.html"] = []byte("{{range .Pages}}{{.URL}};{{end}}")
+	fs.files["content/blog/hello.md"] = []byte("---\ndate: 2024-05-03\n---\nHello")
+	fs.files["content/blog/moved.md"] = []byte("---\ndate: 2023-01-09\nslug: kept-url\n---\nMoved")
+	site := &domain.Site{
+		PagesDir: "content",
+		DistDir:  "dist",
+		Config: map[string]interface{}{
+			"permalinks": map[string]interface{}{"blog": "/blog/:year/:slug/"},
+		},
+	}
+	renderer := NewMockTemplateRenderer()
+	builder := newTestBuilder(site, fs, renderer)
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	if err := builder.buildPages(tmpl, meta.Meta{}); err != nil {
+		t.Fatalf("buildPages failed: %v", err)
+	}
+	for _, path := range []string{"dist/blog/2024/hello/index.html", "dist/blog/2023/kept-url/index.html"} {
+		if fs.written[path] == nil {
+			t.Errorf("Expected %s to be written", path)
+		}
+	}
+	if got := fs.written["dist/blog/index.html"].String(); !strings.Contains(got, "/blog/2024/hello/;/blog/2023/kept-url/;") {
+		t.Errorf("Expected the list page to link the moved pages, got %q", got)
+	}
+}
+
+func TestBuildPages_DuplicateOutput(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.files["content/about/index.html"] = []byte("<p>About</p>")
+	fs.files["content/team.html"] = []byte("---\nurl: /about/\n---\n<p>Team</p>")
+	fs.files["content/old.html"] = []byte("---\nurl: /about/\n---\n<p>Old</p>")
+	site := &domain.Site{PagesDir: "content", DistDir: "dist"}
+	renderer := NewMockTemplateRenderer()
+	builder := newTestBuilder(site, fs, renderer)
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	err := builder.buildPages(tmpl, meta.Meta{})
+	expected := "/about/ is claimed by more than one page: content/about/index.html, content/old.html, content/team.html"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("buildPages error = %v, expected %q", err, expected)
+	}
+	if len(fs.written) != 0 {
+		t.Errorf("Expected nothing to be written, got %d files", len(fs.written))
+	}
+}
@@ -0,0 +1,113 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+// defaultSlugField is the record field that names record pages when the
+// template sets no slug_field
+const defaultSlugField = "slug"
+
+// recordPages expands a record template into one page per record of its data
+// source. The template names the data file in its data_source front matter
+// key and the field that names each page in slug_field:
+//
+//	---
+//	data_source: data/products.yaml
+//	slug_field: sku
+//	---
+//
+// Record pages are written to <slug>/index.html in the template's directory
+// and receive their record as .Record. A record's title and description
+// fields become the page title and meta.
+func (sb *SiteBuilder) recordPages(tmpl *pageSource, source string) ([]*pageSource, error) {
+	field := stringParam(tmpl.page.Params, "slug_field")
+	if field == "" {
+		field = defaultSlugField
+	}
+
+	content, err := sb.fs.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	value, err := decodeData(source, content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: data source must hold a list of records", source)
+	}
+
+	dir := filepath.Dir(tmpl.treePath)
+	slugs := make(map[string]int)
+	var sources []*pageSource
+	for i, item := range list {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: record %d is not a map", source, i+1)
+		}
+		var slug string
+		if v, ok := record[field]; ok && v != nil {
+			slug = urlize(fmt.Sprint(v))
+		}
+		if slug == "" {
+			return nil, fmt.Errorf("%s: record %d has no %q field", source, i+1, field)
+		}
+		if first, ok := slugs[slug]; ok {
+			return nil, fmt.Errorf("%s: records %d and %d have the same slug %q", source, first, i+1, slug)
+		}
+		slugs[slug] = i + 1
+
+		date, err := timeParam(record, "date")
+		if err != nil {
+			return nil, fmt.Errorf("%s: record %d: %w", source, i+1, err)
+		}
+
+		rel := filepath.Join(dir, slug, domain.IndexFile)
+		page := *tmpl.page
+		page.Title = pageTitle(languageRel(page.Language, rel))
+		page.Path = rel
+		page.URL = pageURL(rel)
+		page.Section = pageSection(languageRel(page.Language, rel))
+		page.Record = record
+		if title := stringParam(record, "title"); title != "" {
+			page.Title = title
+			page.Meta.Title = title
+		}
+		if description := stringParam(record, "description"); description != "" {
+			page.Meta.Description = description
+		}
+		if err := page.Meta.Validate(sb.site.AssetsDir); err != nil {
+			return nil, fmt.Errorf("%s: record %d: %w", source, i+1, err)
+		}
+		if !date.IsZero() {
+			page.Date = date
+		}
+		if weight := intParam(record, "weight"); weight != 0 {
+			page.Weight = weight
+		}
+
+		// A record page changes with its template and its own record
+		recordJSON, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("%s: record %d: %w", source, i+1, err)
+		}
+		entry := tmpl.entry
+		entry.SourceHash = hashBytes([]byte(tmpl.entry.SourceHash + hashBytes(recordJSON)))
+
+		sources = append(sources, &pageSource{
+			path:       tmpl.path,
+			treePath:   rel,
+			body:       tmpl.body,
+			isMarkdown: tmpl.isMarkdown,
+			entry:      entry,
+			page:       &page,
+		})
+	}
+	return sources, nil
+}
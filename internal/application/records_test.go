@@ -0,0 +1,96 @@
+package application
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+func TestRecordPages(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.files["data/products.yaml"] = []byte(`- sku: W-1
+  title: Widget
+  price: 9.99
+- sku: G-2
+  title: Gadget
+  description: A handy gadget
+`)
+	fs.files["pages/products/product.html"] = []byte(`---
+data_source: data/products.yaml
+slug_field: sku
+---
+<h1>{{.Record.title}}</h1><p>{{.Record.price}}</p>`)
+	site := &domain.Site{PagesDir: "pages", DistDir: "dist"}
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	tmpl, err := builder.loadPage(meta.Meta{}, "pages/products/product.html")
+	if err != nil {
+		t.Fatalf("loadPage failed: %v", err)
+	}
+	sources, err := builder.recordPages(tmpl, "data/products.yaml")
+	if err != nil {
+		t.Fatalf("recordPages failed: %v", err)
+	}
+
+	if len(sources) != 2 {
+		t.Fatalf("Expected 2 record pages, got %d", len(sources))
+	}
+	widget, gadget := sources[0].page, sources[1].page
+	if widget.Path != "products/w-1/index.html" || widget.URL != "/products/w-1/" || widget.Section != "products" {
+		t.Errorf("Unexpected record page location: %s, %s", widget.Path, widget.URL)
+	}
+	if widget.Title != "Widget" || widget.Meta.Title != "Widget" || widget.Record["price"] != 9.99 {
+		t.Errorf("Unexpected record page: %+v", widget)
+	}
+	if gadget.Meta.Description != "A handy gadget" {
+		t.Errorf("Expected record description in meta, got %q", gadget.Meta.Description)
+	}
+	if sources[0].entry.SourceHash == sources[1].entry.SourceHash || sources[0].entry.SourceHash == tmpl.entry.SourceHash {
+		t.Error("Expected each record page to hash its own record")
+	}
+
+	tmplRenderer := NewMockTemplateRenderer()
+	layout, _ := tmplRenderer.ParseFiles("templates/base.html")
+	if err := builder.renderPages(layout, sources); err != nil {
+		t.Fatalf("renderPages failed: %v", err)
+	}
+	if got := fs.written["dist/products/w-1/index.html"].String(); !strings.Contains(got, "<h1>Widget</h1><p>9.99</p>") {
+		t.Errorf("Unexpected record page output: %s", got)
+	}
+}
+
+func TestRecordPages_CSV(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.files["data/team.csv"] = []byte("slug,name\nada,Ada Lovelace\nlinus,Linus Torvalds\n")
+	site := &domain.Site{PagesDir: "pages"}
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	tmpl := testSource("team/member.html", map[string]interface{}{"data_source": "data/team.csv"})
+	sources, err := builder.recordPages(tmpl, "data/team.csv")
+	if err != nil {
+		t.Fatalf("recordPages failed: %v", err)
+	}
+	if len(sources) != 2 || sources[1].page.Path != "team/linus/index.html" || sources[1].page.Record["name"] != "Linus Torvalds" {
+		t.Errorf("Unexpected record pages from CSV")
+	}
+}
+
+func TestRecordPages_Errors(t *testing.T) {
+	tests := map[string]string{
+		"not a list":     "key: value\n",
+		"not a map":      "- one\n- two\n",
+		"missing slug":   "- title: Untitled\n",
+		"duplicate slug": "- slug: a\n- slug: A\n",
+	}
+	for name, content := range tests {
+		fs := NewMockFileSystem()
+		fs.files["data/items.yaml"] = []byte(content)
+		builder := newTestBuilder(&domain.Site{PagesDir: "pages"}, fs, NewMockTemplateRenderer())
+		tmpl := testSource("items/item.html", nil)
+		if _, err := builder.recordPages(tmpl, "data/items.yaml"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package application
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+const (
+	// defaultRedirectStatus is the status of redirects that set none in config
+	// or front matter; moved pages are moved for good
+	defaultRedirectStatus = 301
+	// cloudflareRedirectStatus is the status Cloudflare uses for lines of the
+	// redirects file without one
+	cloudflareRedirectStatus = 302
+)
+
+// redirectPlaceholder matches a :name placeholder in a redirect
+var redirectPlaceholder = regexp.MustCompile(`:[A-Za-z]\w*`)
+
+// writeRedirects writes the redirects file from the redirects config block and
+// the aliases front matter key of every page:
+//
+//	redirects:
+//	  - from: /docs/v1/*
+//	    to: /docs/:splat
+//	  - from: /team/
+//	    to: /about/
+//	    status: 302
+//
+// Config redirects come first and take precedence. Redirects without a splat
+// or placeholder also get an HTML page at their old path that redirects with
+// a meta refresh, for hosts that do not read the redirects file. Nothing is
+// written when the site has no redirects.
+func (sb *SiteBuilder) writeRedirects(sources []*pageSource) error {
+	redirects, err := sb.redirects(sources)
+	if err != nil {
+		return err
+	}
+	if len(redirects) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	for _, r := range redirects {
+		fmt.Fprintf(&b, "%s %s %d\n", r.From, r.To, r.Status)
+		if dynamicRedirect(r.From) {
+			continue
+		}
+		if rel, err := urlPath(r.From); err == nil && filepath.Ext(rel) == domain.PageExt {
+			dst := filepath.Join(sb.outDir, rel)
+			if err := sb.fs.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			if err := sb.writeFile(dst, redirectPage(r.To)); err != nil {
+				return err
+			}
+		}
+	}
+	return sb.writeFile(filepath.Join(sb.outDir, domain.RedirectsFile), []byte(b.String()))
+}
+
+// redirects returns the redirects of the config block followed by the aliases
+// of every page sorted by path. It fails when two redirects start at the same
+// path or a redirect starts at the path of a page.
+func (sb *SiteBuilder) redirects(sources []*pageSource) ([]domain.Redirect, error) {
+	var redirects []domain.Redirect
+	var errs []error
+	list, _ := sb.site.Config["redirects"].([]interface{})
+	for i, item := range list {
+		entry, _ := item.(map[string]interface{})
+		status, err := numberParam(entry, "status")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("redirect %d: %w", i+1, err))
+			continue
+		}
+		r := domain.Redirect{
+			From:   configString(entry["from"]),
+			To:     configString(entry["to"]),
+			Status: status,
+		}
+		if r.Status == 0 {
+			r.Status = defaultRedirectStatus
+		}
+		if err := validateRedirect(r); err != nil {
+			errs = append(errs, fmt.Errorf("redirect %d: %w", i+1, err))
+			continue
+		}
+		redirects = append(redirects, r)
+	}
+
+	pages := make(map[string]*pageSource, len(sources))
+	var aliases []domain.Redirect
+	for _, src := range sources {
+		pages[src.page.Path] = src
+		for _, alias := range stringList(src.page.Params["aliases"]) {
+			r := domain.Redirect{From: alias, To: src.page.URL, Status: defaultRedirectStatus}
+			if err := validateRedirect(r); err != nil || dynamicRedirect(alias) {
+				errs = append(errs, fmt.Errorf("%s: invalid alias %q", src.path, alias))
+				continue
+			}
+			aliases = append(aliases, r)
+		}
+	}
+	sort.SliceStable(aliases, func(i, j int) bool {
+		return aliases[i].From < aliases[j].From
+	})
+	redirects = append(redirects, aliases...)
+
+	seen := make(map[string]bool, len(redirects))
+	for _, r := range redirects {
+		if seen[r.From] {
+			errs = append(errs, fmt.Errorf("more than one redirect from %s", r.From))
+			continue
+		}
+		seen[r.From] = true
+		if dynamicRedirect(r.From) {
+			continue
+		}
+		if rel, err := urlPath(r.From); err == nil && pages[rel] != nil {
+			errs = append(errs, fmt.Errorf("redirect from %s hides the page %s", r.From, pages[rel].path))
+		}
+	}
+	return redirects, errors.Join(errs...)
+}
+
+// validateRedirect checks that a redirect starts at a URL path, has a target
+// and uses a redirect status
+func validateRedirect(r domain.Redirect) error {
+	switch {
+	case !strings.HasPrefix(r.From, "/") || strings.ContainsAny(r.From, " \t"):
+		return fmt.Errorf("from must be a URL path starting with /, got %q", r.From)
+	case r.To == "" || strings.ContainsAny(r.To, " \t"):
+		return fmt.Errorf("to must be a URL without spaces, got %q", r.To)
+	}
+	switch r.Status {
+	case 301, 302, 303, 307, 308:
+		return nil
+	}
+	return fmt.Errorf("status must be 301, 302, 303, 307 or 308, got %d", r.Status)
+}
+
+// dynamicRedirect reports whether a redirect path has a splat or placeholders
+func dynamicRedirect(from string) bool {
+	return strings.Contains(from, "*") || redirectPlaceholder.MatchString(from)
+}
+
+// parseRedirects reads a redirects file in Cloudflare Pages format: one
+// redirect per line as "from to [status]", with # comments
+func parseRedirects(content []byte) ([]domain.Redirect, error) {
+	var redirects []domain.Redirect
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected from, to and an optional status", n)
+		}
+		r := domain.Redirect{From: fields[0], To: fields[1], Status: cloudflareRedirectStatus}
+		if len(fields) == 3 {
+			status, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid status %q", n, fields[2])
+			}
+			r.Status = status
+		}
+		redirects = append(redirects, r)
+	}
+	return redirects, scanner.Err()
+}
+
+// matchRedirect returns the target and status of the first redirect that
+// matches a request path. A * splat matches the rest of the path and a :name
+// placeholder one path segment; both are filled into the target.
+func matchRedirect(redirects []domain.Redirect, path string) (string, int, bool) {
+	for _, r := range redirects {
+		values, ok := matchPath(r.From, path)
+		if !ok {
+			continue
+		}
+		to := redirectPlaceholder.ReplaceAllStringFunc(r.To, func(name string) string {
+			if value, ok := values[name]; ok {
+				return value
+			}
+			return name
+		})
+		return to, r.Status, true
+	}
+	return "", 0, false
+}
+
+// matchPath reports whether a request path matches a path pattern in the
+// format of Cloudflare Pages redirects and headers, where a * splat matches any
+// text and a :name placeholder one path segment. It returns the matched text
+// of the splat as :splat and of each placeholder by name.
+func matchPath(pattern, path string) (map[string]string, bool) {
+	if !dynamicRedirect(pattern) {
+		return nil, pattern == path
+	}
+
+	var expr strings.Builder
+	var names []string
+	rest := pattern
+	for rest != "" {
+		loc := redirectPlaceholder.FindStringIndex(rest)
+		star := strings.IndexByte(rest, '*')
+		switch {
+		case star >= 0 && (loc == nil || star < loc[0]):
+			expr.WriteString(regexp.QuoteMeta(rest[:star]) + "(.*)")
+			names = append(names, ":splat")
+			rest = rest[star+1:]
+		case loc != nil:
+			expr.WriteString(regexp.QuoteMeta(rest[:loc[0]]) + "([^/]+)")
+			names = append(names, rest[loc[0]:loc[1]])
+			rest = rest[loc[1]:]
+		default:
+			expr.WriteString(regexp.QuoteMeta(rest))
+			rest = ""
+		}
+	}
+	re, err := regexp.Compile("^" + expr.String() + "$")
+	if err != nil {
+		return nil, false
+	}
+	match := re.FindStringSubmatch(path)
+	if match == nil {
+		return nil, false
+	}
+	values := make(map[string]string, len(names))
+	for i, name := range names {
+		values[name] = match[i+1]
+	}
+	return values, true
+}
@@ -0,0 +1,189 @@
+package application
+
+import (
+	"html/template"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+func TestBuildPages_Redirects(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.files["content/index.html"] = []byte("<p>Home</p>")
+	fs.files["content/docs/install.md"] = []byte("---\naliases: [/setup/, /docs/old-install.html]\n---\nInstall")
+	site := &domain.Site{
+		PagesDir: "content",
+		DistDir:  "dist",
+		Config: map[string]interface{}{
+			"redirects": []interface{}{
+				map[string]interface{}{"from": template.HTML("/docs/v1/*"), "to": template.HTML("/docs/:splat")},
+				map[string]interface{}{"from": template.HTML("/team/"), "to": template.HTML("https://example.org/team/"), "status": template.HTML("302")},
+			},
+		},
+	}
+	renderer := NewMockTemplateRenderer()
+	builder := newTestBuilder(site, fs, renderer)
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	if err := builder.buildPages(tmpl, meta.Meta{}); err != nil {
+		t.Fatalf("buildPages failed: %v", err)
+	}
+
+	expected := "/docs/v1/* /docs/:splat 301\n" +
+		"/team/ https://example.org/team/ 302\n" +
+		"/docs/old-install.html /docs/install.html 301\n" +
+		"/setup/ /docs/install.html 301\n"
+	if got := fs.written["dist/_redirects"].String(); got != expected {
+		t.Errorf("_redirects = %q, expected %q", got, expected)
+	}
+	stubs := map[string]string{
+		"dist/team/index.html":       "url=https://example.org/team/",
+		"dist/setup/index.html":      "url=/docs/install.html",
+		"dist/docs/old-install.html": "url=/docs/install.html",
+		"dist/docs/v1/index.html":    "",
+	}
+	for path, target := range stubs {
+		got := fs.written[path]
+		if target == "" {
+			if got != nil {
+				t.Errorf("Expected no stub at %s", path)
+			}
+			continue
+		}
+		if got == nil || !strings.Contains(got.String(), target) {
+			t.Errorf("Expected a stub at %s redirecting with %q, got %v", path, target, got)
+		}
+	}
+}
+
+func TestBuildPages_NoRedirects(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.files["content/index.html"] = []byte("<p>Home</p>")
+	renderer := NewMockTemplateRenderer()
+	builder := newTestBuilder(&domain.Site{PagesDir: "content", DistDir: "dist"}, fs, renderer)
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	if err := builder.buildPages(tmpl, meta.Meta{}); err != nil {
+		t.Fatalf("buildPages failed: %v", err)
+	}
+	if fs.written["dist/_redirects"] != nil {
+		t.Error("Expected no redirects file for a site without redirects")
+	}
+}
+
+func TestRedirects_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   []interface{}
+		aliases  []interface{}
+		expected string
+	}{
+		{
+			"relative from",
+			[]interface{}{map[string]interface{}{"from": "old/", "to": "/new/"}},
+			nil,
+			`redirect 1: from must be a URL path starting with /, got "old/"`,
+		},
+		{
+			"missing to",
+			[]interface{}{map[string]interface{}{"from": "/old/"}},
+			nil,
+			`redirect 1: to must be a URL without spaces, got ""`,
+		},
+		{
+			"status",
+			[]interface{}{map[string]interface{}{"from": "/old/", "to": "/new/", "status": 200}},
+			nil,
+			"redirect 1: status must be 301, 302, 303, 307 or 308, got 200",
+		},
+		{
+			"status not a number",
+			[]interface{}{map[string]interface{}{"from": "/old/", "to": "/new/", "status": "found"}},
+			nil,
+			`redirect 1: invalid status "found", expected a number`,
+		},
+		{
+			"dynamic alias",
+			nil,
+			[]interface{}{"/posts/*"},
+			`pages/blog/post.html: invalid alias "/posts/*"`,
+		},
+		{
+			"duplicate",
+			[]interface{}{map[string]interface{}{"from": "/old/", "to": "/new/"}},
+			[]interface{}{"/old/"},
+			"more than one redirect from /old/",
+		},
+		{
+			"hides page",
+			nil,
+			[]interface{}{"/about/"},
+			"redirect from /about/ hides the page pages/about/index.html",
+		},
+	}
+	for _, tt := range tests {
+		site := &domain.Site{Config: map[string]interface{}{"redirects": tt.config}}
+		builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+		post := testSource("blog/post.html", map[string]interface{}{"aliases": tt.aliases})
+		about := testSource("about/index.html", nil)
+
+		_, err := builder.redirects([]*pageSource{post, about})
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("%s: error = %v, expected %q", tt.name, err, tt.expected)
+		}
+	}
+}
+
+func TestParseRedirects(t *testing.T) {
+	content := "# Moved docs\n/docs/v1/* /docs/:splat 301\n\n/old /new\n"
+	redirects, err := parseRedirects([]byte(content))
+	if err != nil {
+		t.Fatalf("parseRedirects failed: %v", err)
+	}
+	expected := []domain.Redirect{
+		{From: "/docs/v1/*", To: "/docs/:splat", Status: 301},
+		{From: "/old", To: "/new", Status: 302},
+	}
+	if len(redirects) != len(expected) || redirects[0] != expected[0] || redirects[1] != expected[1] {
+		t.Errorf("parseRedirects = %+v, expected %+v", redirects, expected)
+	}
+
+	if _, err := parseRedirects([]byte("/old\n")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected a line error, got %v", err)
+	}
+	if _, err := parseRedirects([]byte("/old /new moved\n")); err == nil || !strings.Contains(err.Error(), `invalid status "moved"`) {
+		t.Errorf("Expected a status error, got %v", err)
+	}
+}
+
+func TestMatchRedirect(t *testing.T) {
+	redirects := []domain.Redirect{
+		{From: "/team/", To: "/about/", Status: 302},
+		{From: "/docs/v1/*", To: "/docs/:splat", Status: 301},
+		{From: "/blog/:year/:slug/", To: "/posts/:slug/?from=:year", Status: 308},
+		{From: "/files/*.zip", To: "https://cdn.example.com/:splat.zip", Status: 301},
+	}
+	tests := []struct {
+		path   string
+		to     string
+		status int
+	}{
+		{"/team/", "/about/", 302},
+		{"/team", "", 0},
+		{"/docs/v1/guide/install.html", "/docs/guide/install.html", 301},
+		{"/docs/v1/", "/docs/", 301},
+		{"/blog/2024/hello/", "/posts/hello/?from=2024", 308},
+		{"/blog/2024/hello/extra/", "", 0},
+		{"/files/a/b.zip", "https://cdn.example.com/a/b.zip", 301},
+		{"/about/", "", 0},
+	}
+	for _, tt := range tests {
+		to, status, ok := matchRedirect(redirects, tt.path)
+		if ok != (tt.to != "") || to != tt.to || status != tt.status {
+			t.Errorf("matchRedirect(%s) = %q, %d, %v, expected %q, %d", tt.path, to, status, ok, tt.to, tt.status)
+		}
+	}
+}
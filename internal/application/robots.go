@@ -0,0 +1,59 @@
+package application
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const robotsFile = "robots.txt"
+
+// writeRobots writes robots.txt from the robots config block. Each entry of
+// its rules list names one or more user agents with their allow and disallow
+// paths:
+//
+//	robots:
+//	  rules:
+//	    - user_agent: "*"
+//	      disallow: [/drafts/]
+//
+// The generated sitemap is linked when base_url is set. Nothing is written
+// when there is no robots block.
+func (sb *SiteBuilder) writeRobots() error {
+	config, ok := sb.site.Config["robots"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var groups []string
+	rules, _ := config["rules"].([]interface{})
+	for _, r := range rules {
+		rule, _ := r.(map[string]interface{})
+		agents := stringList(rule["user_agent"])
+		if len(agents) == 0 {
+			agents = []string{"*"}
+		}
+
+		var b strings.Builder
+		for _, agent := range agents {
+			b.WriteString("User-agent: " + agent + "\n")
+		}
+		allow := stringList(rule["allow"])
+		disallow := stringList(rule["disallow"])
+		for _, path := range allow {
+			b.WriteString("Allow: " + path + "\n")
+		}
+		for _, path := range disallow {
+			b.WriteString("Disallow: " + path + "\n")
+		}
+		if len(allow) == 0 && len(disallow) == 0 {
+			// An empty disallow rule allows everything
+			b.WriteString("Disallow:\n")
+		}
+		groups = append(groups, b.String())
+	}
+
+	if baseURL := sb.baseURL(); baseURL != "" {
+		groups = append(groups, "Sitemap: "+baseURL+"/"+sitemapFile+"\n")
+	}
+	return sb.writeFile(filepath.Join(sb.outDir, robotsFile), []byte(strings.Join(groups, "\n")))
+}
@@ -0,0 +1,70 @@
+package application
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+func TestWriteRobots(t *testing.T) {
+	site := &domain.Site{
+		DistDir: "dist",
+		Config: map[string]interface{}{
+			"base_url": template.HTML("https://example.com"),
+			"robots": map[string]interface{}{
+				"rules": []interface{}{
+					map[string]interface{}{
+						"user_agent": template.HTML("*"),
+						"allow":      []interface{}{template.HTML("/")},
+						"disallow":   []interface{}{template.HTML("/drafts/"), template.HTML("/admin/")},
+					},
+					map[string]interface{}{
+						"user_agent": []interface{}{template.HTML("GPTBot"), template.HTML("CCBot")},
+						"disallow":   template.HTML("/"),
+					},
+					map[string]interface{}{
+						"user_agent": template.HTML("Googlebot"),
+					},
+				},
+			},
+		},
+	}
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	if err := builder.writeRobots(); err != nil {
+		t.Fatalf("writeRobots failed: %v", err)
+	}
+
+	expected := `User-agent: *
+Allow: /
+Disallow: /drafts/
+Disallow: /admin/
+
+User-agent: GPTBot
+User-agent: CCBot
+Disallow: /
+
+User-agent: Googlebot
+Disallow:
+
+Sitemap: https://example.com/sitemap.xml
+`
+	if got := fs.written["dist/robots.txt"].String(); got != expected {
+		t.Errorf("Unexpected robots.txt:\n%s", got)
+	}
+}
+
+func TestWriteRobots_NoConfig(t *testing.T) {
+	site := &domain.Site{DistDir: "dist", Config: map[string]interface{}{}}
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	if err := builder.writeRobots(); err != nil {
+		t.Fatalf("writeRobots failed: %v", err)
+	}
+	if _, ok := fs.written["dist/robots.txt"]; ok {
+		t.Error("Expected no robots.txt without a robots block")
+	}
+}
@@ -0,0 +1,117 @@
+package application
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+// linkSections gives every list page the pages of its section. A list page is
+// the index page of a directory; its section holds the other pages in that
+// directory and the index pages of its immediate subdirectories. The pages are
+// sorted by the list page's sort_by front matter key, and the list page is
+// rebuilt whenever one of them changes.
+func linkSections(sources []*pageSource) {
+	lists := make(map[string]*pageSource)
+	for _, src := range sources {
+		if filepath.Base(src.treePath) == domain.IndexFile {
+			lists[filepath.Dir(src.treePath)] = src
+		}
+	}
+
+	children := make(map[string][]*pageSource)
+	for _, src := range sources {
+		parent, ok := parentSection(src.treePath)
+		if !ok {
+			continue
+		}
+		if _, ok := lists[parent]; ok {
+			children[parent] = append(children[parent], src)
+		}
+	}
+
+	for dir, list := range lists {
+		pages := make([]*domain.Page, 0, len(children[dir]))
+		for _, child := range children[dir] {
+			pages = append(pages, child.page)
+		}
+		sortPages(pages, stringParam(list.page.Params, "sort_by"))
+		list.page.Pages = pages
+		list.entry.DepsHash = sourcesHash(children[dir])
+	}
+}
+
+// sourcesHash returns a hash of the source files of pages, for the pages that
+// list them
+func sourcesHash(sources []*pageSource) string {
+	var deps []string
+	for _, src := range sources {
+		deps = append(deps, src.entry.Source+":"+src.entry.SourceHash)
+	}
+	sort.Strings(deps)
+	return hashBytes([]byte(strings.Join(deps, "\n")))
+}
+
+// parentSection returns the directory whose list page includes the page at
+// rel. Index pages belong to the section above their own directory; the home
+// page belongs to none.
+func parentSection(rel string) (string, bool) {
+	dir := filepath.Dir(rel)
+	if filepath.Base(rel) != domain.IndexFile {
+		return dir, true
+	}
+	if dir == "." {
+		return "", false
+	}
+	return filepath.Dir(dir), true
+}
+
+// pageSection returns the top-level directory of a page relative to the pages
+// directory, or an empty string for pages at the root
+func pageSection(rel string) string {
+	parts := strings.SplitN(filepath.ToSlash(rel), "/", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0]
+}
+
+// sortPages sorts pages in place. By default pages are ordered by weight
+// (unweighted pages last), then newest date first, then the title pages are
+// listed with; "date", "weight" and "title" sort by that field alone. Ties fall back to the path so
+// the order is stable across builds.
+func sortPages(pages []*domain.Page, by string) {
+	sort.SliceStable(pages, func(i, j int) bool {
+		a, b := pages[i], pages[j]
+		switch by {
+		case "date":
+			if !a.Date.Equal(b.Date) {
+				return a.Date.After(b.Date)
+			}
+		case "weight":
+			if a.Weight != b.Weight {
+				return a.Weight < b.Weight
+			}
+		case "title":
+			if itemTitle(a) != itemTitle(b) {
+				return itemTitle(a) < itemTitle(b)
+			}
+		default:
+			if a.Weight != b.Weight {
+				if a.Weight == 0 || b.Weight == 0 {
+					return b.Weight == 0
+				}
+				return a.Weight < b.Weight
+			}
+			if !a.Date.Equal(b.Date) {
+				return a.Date.After(b.Date)
+			}
+			if itemTitle(a) != itemTitle(b) {
+				return itemTitle(a) < itemTitle(b)
+			}
+		}
+		return a.Path < b.Path
+	})
+}
@@ -0,0 +1,145 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+func testSource(rel string, params map[string]interface{}) *pageSource {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	date, _ := timeParam(params, "date")
+	return &pageSource{
+		path:     "pages/" + rel,
+		treePath: rel,
+		entry:    cacheEntry{Source: "pages/" + rel, SourceHash: rel},
+		page: &domain.Page{
+			Title:   pageTitle(rel),
+			Path:    rel,
+			URL:     pageURL(rel),
+			Meta:    meta.Meta{Title: stringParam(params, "title")},
+			Params:  params,
+			Section: pageSection(rel),
+			Date:    date,
+			Weight:  intParam(params, "weight"),
+		},
+	}
+}
+
+func pagePaths(pages []*domain.Page) []string {
+	var paths []string
+	for _, p := range pages {
+		paths = append(paths, p.Path)
+	}
+	return paths
+}
+
+func TestLinkSections(t *testing.T) {
+	home := testSource("index.html", nil)
+	about := testSource("about/index.html", nil)
+	blog := testSource("blog/index.html", map[string]interface{}{"sort_by": "date"})
+	older := testSource("blog/older.html", map[string]interface{}{"date": "2024-01-01"})
+	newer := testSource("blog/newer.html", map[string]interface{}{"date": "2024-06-01"})
+	bundle := testSource("blog/bundle/index.html", map[string]interface{}{"date": "2024-03-01"})
+	nested := testSource("blog/bundle/extra.html", nil)
+
+	linkSections([]*pageSource{home, about, blog, older, newer, bundle, nested})
+
+	if got := pagePaths(home.page.Pages); len(got) != 2 || got[0] != "about/index.html" || got[1] != "blog/index.html" {
+		t.Errorf("Home pages = %v", got)
+	}
+	expected := []string{"blog/newer.html", "blog/bundle/index.html", "blog/older.html"}
+	if got := pagePaths(blog.page.Pages); len(got) != 3 || got[0] != expected[0] || got[1] != expected[1] || got[2] != expected[2] {
+		t.Errorf("Blog pages = %v, expected %v", got, expected)
+	}
+	if got := pagePaths(bundle.page.Pages); len(got) != 1 || got[0] != "blog/bundle/extra.html" {
+		t.Errorf("Bundle pages = %v", got)
+	}
+	if older.page.Pages != nil {
+		t.Error("Regular pages should not list pages")
+	}
+	if blog.page.Pages[0].Params["date"] != "2024-06-01" {
+		t.Error("Listed pages should carry their front matter")
+	}
+
+	// The list page depends on its section's pages
+	before := blog.entry.DepsHash
+	older.entry.SourceHash = "changed"
+	linkSections([]*pageSource{home, about, blog, older, newer, bundle, nested})
+	if blog.entry.DepsHash == before {
+		t.Error("Expected list page dependencies to change")
+	}
+}
+
+func TestSortPages(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	pages := func() []*domain.Page {
+		return []*domain.Page{
+			{Path: "a", Title: "C", Date: day(1)},
+			{Path: "b", Title: "A", Weight: 2, Date: day(3)},
+			{Path: "c", Title: "B", Weight: 1, Date: day(2)},
+			{Path: "d", Title: "D", Date: day(4)},
+		}
+	}
+
+	tests := map[string][]string{
+		"":       {"c", "b", "d", "a"},
+		"date":   {"d", "b", "c", "a"},
+		"weight": {"a", "d", "c", "b"},
+		"title":  {"b", "c", "a", "d"},
+	}
+	for by, expected := range tests {
+		ps := pages()
+		sortPages(ps, by)
+		got := pagePaths(ps)
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("sortPages(%q) = %v, expected %v", by, got, expected)
+				break
+			}
+		}
+	}
+}
+
+func TestSortPages_FrontMatterTitle(t *testing.T) {
+	pages := []*domain.Page{
+		testSource("blog/banana.html", nil).page,
+		testSource("blog/zebra.html", map[string]interface{}{"title": "Apple"}).page,
+	}
+	sortPages(pages, "title")
+	if got := pagePaths(pages); got[0] != "blog/zebra.html" {
+		t.Errorf("sortPages(title) = %v, expected the front-matter title to be sorted by", got)
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	tests := map[string]string{
+		"index.html":        "/",
+		"about/index.html":  "/about/",
+		"blog/my-post.html": "/blog/my-post.html",
+	}
+	for rel, expected := range tests {
+		if got := pageURL(rel); got != expected {
+			t.Errorf("pageURL(%q) = %q, expected %q", rel, got, expected)
+		}
+	}
+}
+
+func TestPageSection(t *testing.T) {
+	tests := map[string]string{
+		"index.html":            "",
+		"404.html":              "",
+		"blog/index.html":       "blog",
+		"blog/2024/post.html":   "blog",
+		"docs/guide/index.html": "docs",
+	}
+	for rel, expected := range tests {
+		if got := pageSection(rel); got != expected {
+			t.Errorf("pageSection(%q) = %q, expected %q", rel, got, expected)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package application
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+var (
+	// shortcodeTag matches {{< name key="value" >}} and {{< /name >}}
+	shortcodeTag = regexp.MustCompile(`\{\{<\s*(/?)\s*([\w-]+)(.*?)(/?)\s*>\}\}`)
+	// shortcodeArg matches one named argument: key="value", key='value' or key=value
+	shortcodeArg = regexp.MustCompile(`^\s*([\w-]+)=(?:"([^"]*)"|'([^']*)'|([^\s"'/]+))`)
+)
+
+// shortcodePlaceholder marks where the output of a shortcode goes while the
+// body around it is executed or converted. It contains only letters and
+// digits so that neither templates nor Markdown change it.
+func shortcodePlaceholder(i int) string {
+	return "stwshortcode" + strconv.Itoa(i) + "end"
+}
+
+// shortcode is a shortcode call found in a page body
+type shortcode struct {
+	start, end int // byte range of the call, including a closing tag
+	name       string
+	params     map[string]string
+	inner      string
+}
+
+// renderShortcodes renders the shortcodes called in body with the templates
+// in templates/shortcodes/ and replaces each call with a placeholder. The
+// outputs are put back with restoreShortcodes once the rest of the body is
+// rendered. The inner content of paired shortcodes is converted from Markdown
+// on Markdown pages.
+func (sb *SiteBuilder) renderShortcodes(tmpl *template.Template, body string, page *domain.Page, markdown bool) (string, []string, error) {
+	if !strings.Contains(body, "{{<") {
+		return body, nil, nil
+	}
+	calls, err := parseShortcodes(body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var b strings.Builder
+	var outputs []string
+	last := 0
+	for _, call := range calls {
+		name := domain.ShortcodesDir + "/" + call.name + domain.PageExt
+		if tmpl.Lookup(name) == nil {
+			return "", nil, fmt.Errorf("shortcode %q not found: %s does not exist", call.name, filepath.Join(sb.site.TemplatesDir, name))
+		}
+
+		inner, err := sb.renderInner(tmpl, call.inner, page, markdown)
+		if err != nil {
+			return "", nil, fmt.Errorf("shortcode %q: %w", call.name, err)
+		}
+		var buf bytes.Buffer
+		data := domain.Shortcode{Name: call.name, Params: call.params, Inner: inner, Page: page}
+		if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", nil, fmt.Errorf("shortcode %q: %w", call.name, err)
+		}
+
+		b.WriteString(body[last:call.start])
+		b.WriteString(shortcodePlaceholder(len(outputs)))
+		outputs = append(outputs, buf.String())
+		last = call.end
+	}
+	b.WriteString(body[last:])
+	return b.String(), outputs, nil
+}
+
+// renderInner renders the inner content of a paired shortcode, including the
+// shortcodes nested in it
+func (sb *SiteBuilder) renderInner(tmpl *template.Template, inner string, page *domain.Page, markdown bool) (template.HTML, error) {
+	if inner == "" {
+		return "", nil
+	}
+	inner, outputs, err := sb.renderShortcodes(tmpl, inner, page, markdown)
+	if err != nil {
+		return "", err
+	}
+	if markdown {
+		html, err := sb.markdown.Convert([]byte(inner))
+		if err != nil {
+			return "", err
+		}
+		inner = string(html)
+	}
+	return template.HTML(restoreShortcodes(inner, outputs)), nil
+}
+
+// restoreShortcodes replaces shortcode placeholders with their outputs. A
+// placeholder that Markdown wrapped in a paragraph of its own is replaced
+// together with the paragraph.
+func restoreShortcodes(content string, outputs []string) string {
+	// Replace the highest placeholders first so that stwshortcode1end is
+	// never mistaken for part of stwshortcode11end
+	for i := len(outputs) - 1; i >= 0; i-- {
+		placeholder := shortcodePlaceholder(i)
+		content = strings.Replace(content, "<p>"+placeholder+"</p>", outputs[i], 1)
+		content = strings.Replace(content, placeholder, outputs[i], 1)
+	}
+	return content
+}
+
+// parseShortcodes returns the top-level shortcode calls in body in order. An
+// opening tag with a matching closing tag later in the body is a paired
+// shortcode; any other opening tag stands alone.
+func parseShortcodes(body string) ([]shortcode, error) {
+	tags := shortcodeTag.FindAllStringSubmatchIndex(body, -1)
+	var calls []shortcode
+	for i := 0; i < len(tags); i++ {
+		tag := tags[i]
+		name := body[tag[4]:tag[5]]
+		if tag[3] > tag[2] {
+			return nil, fmt.Errorf("closing shortcode tag %q has no opening tag", name)
+		}
+		params, err := parseShortcodeArgs(body[tag[6]:tag[7]])
+		if err != nil {
+			return nil, fmt.Errorf("shortcode %q: %w", name, err)
+		}
+		call := shortcode{start: tag[0], end: tag[1], name: name, params: params}
+
+		// Self-closing tags such as {{< figure src="a.jpg" />}} have no inner content
+		if tag[9] == tag[8] {
+			if closing := matchingClose(body, tags, i); closing > 0 {
+				call.inner = body[tag[1]:tags[closing][0]]
+				call.end = tags[closing][1]
+				i = closing
+			}
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// matchingClose returns the index of the closing tag that matches the opening
+// tag at tags[open], skipping nested pairs of the same shortcode, or -1 if it
+// has none
+func matchingClose(body string, tags [][]int, open int) int {
+	name := body[tags[open][4]:tags[open][5]]
+	depth := 0
+	for i := open + 1; i < len(tags); i++ {
+		tag := tags[i]
+		if body[tag[4]:tag[5]] != name {
+			continue
+		}
+		closing := tag[3] > tag[2]
+		selfClosing := tag[9] > tag[8]
+		switch {
+		case closing && depth == 0:
+			return i
+		case closing:
+			depth--
+		case !selfClosing && hasClose(body, tags, i):
+			depth++
+		}
+	}
+	return -1
+}
+
+// hasClose reports whether a closing tag of the shortcode at tags[i] follows it
+func hasClose(body string, tags [][]int, i int) bool {
+	name := body[tags[i][4]:tags[i][5]]
+	for _, tag := range tags[i+1:] {
+		if tag[3] > tag[2] && body[tag[4]:tag[5]] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseShortcodeArgs parses named arguments such as type="warning" id=42
+func parseShortcodeArgs(args string) (map[string]string, error) {
+	params := make(map[string]string)
+	for strings.TrimSpace(args) != "" {
+		m := shortcodeArg.FindStringSubmatch(args)
+		if m == nil {
+			return nil, fmt.Errorf("invalid argument %q, expected key=\"value\"", strings.TrimSpace(args))
+		}
+		params[m[1]] = m[2] + m[3] + m[4]
+		args = args[len(m[0]):]
+	}
+	return params, nil
+}
@@ -0,0 +1,100 @@
+package application
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+func shortcodeTemplates(t *testing.T) *template.Template {
+	t.Helper()
+	tmpl := template.New("base.html")
+	shortcodes := map[string]string{
+		"callout": `<div class="callout {{.Params.type}}">{{.Inner}}</div>`,
+		"youtube": `<iframe src="https://www.youtube.com/embed/{{.Params.id}}" title="{{.Page.Title}}"></iframe>`,
+		"tabs":    `<div class="tabs">{{.Inner}}</div>`,
+		"tab":     `<section title="{{.Params.title}}">{{.Inner}}</section>`,
+	}
+	for name, text := range shortcodes {
+		if _, err := tmpl.New(domain.ShortcodesDir + "/" + name + domain.PageExt).Parse(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmpl
+}
+
+func TestPageContent_Shortcodes(t *testing.T) {
+	builder := newTestBuilder(&domain.Site{PagesDir: "pages"}, NewMockFileSystem(), NewMockTemplateRenderer())
+	tmpl := shortcodeTemplates(t)
+
+	tests := []struct {
+		name     string
+		body     string
+		markdown bool
+		expected string
+	}{
+		{
+			name:     "html page",
+			body:     `<h1>{{.Title}}</h1>{{< youtube id="abc123" >}}`,
+			expected: `<h1>Video</h1><iframe src="https://www.youtube.com/embed/abc123" title="Video"></iframe>`,
+		},
+		{
+			name:     "self-closing",
+			body:     `{{< youtube id=abc123 />}}`,
+			expected: `<iframe src="https://www.youtube.com/embed/abc123" title="Video"></iframe>`,
+		},
+		{
+			name:     "escaped arguments",
+			body:     `{{< callout type='"><script>' >}}`,
+			expected: `<div class="callout &#34;&gt;&lt;script&gt;"></div>`,
+		},
+		{
+			name:     "markdown inner content",
+			body:     "Intro\n\n{{< callout type=\"warning\" >}}**Careful**{{< /callout >}}\n",
+			markdown: true,
+			expected: "<p>Intro</p>\n<div class=\"callout warning\"><p><strong>Careful</strong></p>\n</div>",
+		},
+		{
+			name:     "nested",
+			body:     `{{< tabs >}}{{< tab title="Go" >}}go{{< /tab >}}{{< tab title="Rust" >}}rust{{< /tab >}}{{< /tabs >}}`,
+			expected: `<div class="tabs"><section title="Go">go</section><section title="Rust">rust</section></div>`,
+		},
+		{
+			name:     "nested same name",
+			body:     `{{< callout type="a" >}}{{< callout type="b" >}}x{{< /callout >}}{{< /callout >}}`,
+			expected: `<div class="callout a"><div class="callout b">x</div></div>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &pageSource{body: tt.body, isMarkdown: tt.markdown}
+			content, err := builder.pageContent(tmpl, src, &domain.Page{Title: "Video"})
+			if err != nil {
+				t.Fatalf("pageContent failed: %v", err)
+			}
+			if got := strings.TrimSpace(string(content)); got != tt.expected {
+				t.Errorf("Content = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPageContent_ShortcodeErrors(t *testing.T) {
+	builder := newTestBuilder(&domain.Site{PagesDir: "pages", TemplatesDir: "templates"}, NewMockFileSystem(), NewMockTemplateRenderer())
+	tmpl := shortcodeTemplates(t)
+
+	tests := map[string]string{
+		`{{< figure src="a.jpg" >}}`: `shortcode "figure" not found`,
+		`{{< callout warning >}}`:    `invalid argument "warning"`,
+		`text{{< /callout >}}`:       `closing shortcode tag "callout" has no opening tag`,
+	}
+	for body, expected := range tests {
+		_, err := builder.pageContent(tmpl, &pageSource{body: body}, &domain.Page{})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("pageContent(%q) error = %v, expected %q", body, err, expected)
+		}
+	}
+}
@@ -2,10 +2,16 @@ package application
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"html/template"
 	"io/fs"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/EmiraLabs/stw-cli/internal/domain"
 	"github.com/EmiraLabs/stw-cli/internal/infrastructure"
@@ -17,6 +23,35 @@ type SiteBuilder struct {
 	site     *domain.Site
 	fs       infrastructure.FileSystem
 	renderer infrastructure.TemplateRenderer
+	markdown infrastructure.MarkdownConverter
+	minifier infrastructure.Minifier
+
+	// Per-build state used for incremental builds
+	outDir        string
+	cache         *buildCache
+	templatesHash string
+	configHash    string
+	pagesHash     string
+	siteTemplates map[string]bool // templates that read the site graph
+
+	// Data files loaded for the current build
+	data     map[string]interface{}
+	dataHash string
+
+	// Languages and translation catalogs loaded for the current build
+	languages    []*domain.Language
+	languageMeta map[string]meta.Meta
+	i18nHash     string
+
+	// Asset output names by path for the current build, and the content of
+	// fingerprinted assets changed by rewriting or minification
+	assets       map[string]string
+	assetContent map[string][]byte
+	assetsHash   string
+
+	// Pages left out of the current build as unpublished
+	skipped []skippedPage
+	now     func() time.Time
 }
 
 // NewSiteBuilder creates a new SiteBuilder
@@ -25,141 +60,590 @@ func NewSiteBuilder(site *domain.Site, fs infrastructure.FileSystem, renderer in
 		site:     site,
 		fs:       fs,
 		renderer: renderer,
+		markdown: infrastructure.NewGoldmarkConverter(),
+		minifier: infrastructure.NewBasicMinifier(),
+		now:      time.Now,
 	}
 }
 
-// Build builds the site
+// Build builds the site into a staging directory and swaps it into the dist
+// directory only when every step succeeds, so a failed build leaves the last
+// good output in place. When the site has a cache directory, outputs whose
+// inputs are unchanged since the previous build are reused instead of rebuilt.
 func (sb *SiteBuilder) Build() error {
-	// Remove and recreate dist dir
-	if err := sb.fs.RemoveAll(sb.site.DistDir); err != nil {
+	sb.cache = nil
+	if sb.site.CacheDir != "" {
+		sb.cache = loadBuildCache(sb.fs, sb.site.CacheDir)
+	}
+
+	staging := sb.siblingDir("staging")
+	if err := sb.fs.RemoveAll(staging); err != nil {
 		return err
 	}
-	if err := sb.fs.MkdirAll(sb.site.DistDir, 0755); err != nil {
+	if err := sb.fs.MkdirAll(staging, 0755); err != nil {
 		return err
 	}
+	sb.outDir = staging
+
+	if err := sb.generate(); err != nil {
+		sb.fs.RemoveAll(staging)
+		return err
+	}
+	if err := sb.swapDist(staging); err != nil {
+		return err
+	}
+
+	return sb.cache.save(sb.fs, sb.site.CacheDir)
+}
 
+// generate renders pages and copies assets into the output directory
+func (sb *SiteBuilder) generate() error {
 	// Load site meta
 	siteMeta := meta.LoadSiteMeta(sb.site.Config)
 
+	// Load data files
+	if err := sb.loadData(); err != nil {
+		return err
+	}
+	if err := sb.loadLanguages(); err != nil {
+		return err
+	}
+	if err := sb.loadAssets(); err != nil {
+		return err
+	}
+
 	// Parse templates
-	tmpl, err := sb.renderer.ParseFiles(
-		filepath.Join(sb.site.TemplatesDir, domain.BaseTemplate),
-		filepath.Join(sb.site.TemplatesDir, domain.HeaderTemplateFile),
-		filepath.Join(sb.site.TemplatesDir, domain.FooterTemplateFile),
-		filepath.Join(sb.site.TemplatesDir, domain.HeadTemplateFile),
-	)
+	templateFiles, err := sb.discoverTemplates()
 	if err != nil {
 		return err
 	}
-	// Set the template in renderer if possible, but since interface, perhaps cast or change.
+	tmpl, err := sb.renderer.ParseTemplates(sb.site.TemplatesDir, templateFiles...)
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(template.FuncMap{"asset": sb.assetURL})
+
+	if sb.cache != nil {
+		if err := sb.hashInputs(templateFiles); err != nil {
+			return err
+		}
+	}
 
 	// For simplicity, use the tmpl directly
 	if err := sb.buildPages(tmpl, siteMeta); err != nil {
 		return err
 	}
-	if err := sb.copyAssets(); err != nil {
+	if err := sb.writeLanguageRedirect(); err != nil {
+		return err
+	}
+	if err := sb.writeRobots(); err != nil {
+		return err
+	}
+	if err := sb.writeHeaders(); err != nil {
+		return err
+	}
+	if err := sb.writeAssetsManifest(); err != nil {
+		return err
+	}
+	return sb.copyAssets()
+}
+
+// discoverTemplates returns every template file under the templates directory
+// in walk order
+func (sb *SiteBuilder) discoverTemplates() ([]string, error) {
+	var files []string
+	err := sb.fs.WalkDir(sb.site.TemplatesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != domain.PageExt {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, err
+}
+
+// layoutTemplate returns the name of the template a page is rendered with:
+// base.html, or the layout named in its front matter
+func (sb *SiteBuilder) layoutTemplate(tmpl *template.Template, layout string) (string, error) {
+	name := layoutName(layout)
+	if layout == "" {
+		if tmpl.Lookup(name) == nil {
+			return "", fmt.Errorf("base template not found: %s does not exist", filepath.Join(sb.site.TemplatesDir, name))
+		}
+		return name, nil
+	}
+	if tmpl.Lookup(name) == nil {
+		return "", fmt.Errorf("layout %q not found: %s does not exist", layout, filepath.Join(sb.site.TemplatesDir, name))
+	}
+	return name, nil
+}
+
+// layoutName returns the name of the template for a layout named in front
+// matter, or of base.html when no layout is named
+func layoutName(layout string) string {
+	if layout == "" {
+		return domain.BaseTemplate
+	}
+	return domain.LayoutsDir + "/" + strings.TrimSuffix(layout, domain.PageExt) + domain.PageExt
+}
+
+// siblingDir returns a hidden directory next to the dist directory, such as
+// .dist-staging, so it can be renamed into place on the same file system
+func (sb *SiteBuilder) siblingDir(suffix string) string {
+	dist := filepath.Clean(sb.site.DistDir)
+	return filepath.Join(filepath.Dir(dist), "."+filepath.Base(dist)+"-"+suffix)
+}
+
+// swapDist replaces the dist directory with the staging directory. The
+// previous output is moved aside first and removed once the new output is in
+// place. The two renames are not atomic: between them the dist directory does
+// not exist, so a server reading it must not serve files during a build, as
+// stw serve does.
+func (sb *SiteBuilder) swapDist(staging string) error {
+	backup := sb.siblingDir("old")
+	if err := sb.fs.RemoveAll(backup); err != nil {
+		return err
+	}
+	hadDist := sb.exists(sb.site.DistDir)
+	if hadDist {
+		if err := sb.fs.Rename(sb.site.DistDir, backup); err != nil {
+			return err
+		}
+	}
+	if err := sb.fs.Rename(staging, sb.site.DistDir); err != nil {
+		if hadDist {
+			sb.fs.Rename(backup, sb.site.DistDir)
+		}
 		return err
 	}
+	return sb.fs.RemoveAll(backup)
+}
+
+// hashInputs computes the hashes of the inputs shared by every page: the
+// templates, and the site configuration together with the data files,
+// translation catalogs, HTML minification and whether pages are rendered for
+// stw serve
+func (sb *SiteBuilder) hashInputs(templateFiles []string) error {
+	var templates []byte
+	for _, file := range templateFiles {
+		content, err := sb.fs.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, file...)
+		templates = append(templates, hashBytes(content)...)
+	}
+	sb.templatesHash = hashBytes(templates)
 
+	config, err := json.Marshal(sb.site.Config)
+	if err != nil {
+		return err
+	}
+	inputs := sb.dataHash + sb.i18nHash + sb.assetsHash
+	if sb.site.MinifyHTML {
+		inputs += "minify-html"
+	}
+	if sb.site.EnableAutoReload {
+		// Pages rendered for stw serve load the live reload client through .IsDev
+		inputs += "dev"
+	}
+	sb.configHash = hashBytes(append(config, inputs...))
 	return nil
 }
 
+// reuse links output from the live dist directory into the output directory
+// when it was last built from the same inputs, and reports whether it did.
+// Outputs are hard-linked so unchanged files are not read and written again,
+// and copied where the file system does not support hard links. Outputs are
+// never written in place, so the linked files stay unchanged.
+func (sb *SiteBuilder) reuse(output string, entry cacheEntry) (bool, error) {
+	live := filepath.Join(sb.site.DistDir, output)
+	if !sb.cache.fresh(output, entry) || !sb.exists(live) {
+		return false, nil
+	}
+	dst := filepath.Join(sb.outDir, output)
+	if err := sb.fs.Link(live, dst); err != nil {
+		if err := sb.copyFile(live, dst); err != nil {
+			return false, err
+		}
+	}
+	sb.cache.record(output, entry)
+	return true, nil
+}
+
+// exists reports whether the named file exists
+func (sb *SiteBuilder) exists(name string) bool {
+	_, err := sb.fs.Stat(name)
+	return err == nil
+}
+
 func (sb *SiteBuilder) buildPages(tmpl *template.Template, siteMeta meta.Meta) error {
-	return sb.fs.WalkDir(sb.site.PagesDir, func(path string, d fs.DirEntry, err error) error {
+	sources, err := sb.loadPages(siteMeta)
+	if err != nil {
+		return err
+	}
+	sb.reportSkipped()
+	linkSections(sources)
+	sb.siteTemplates = siteTemplates(tmpl)
+	for _, group := range sb.groupByLanguage(sources) {
+		graph := sb.linkSite(group.sources)
+		generated, err := sb.linkTaxonomies(group.sources, group.lang, sb.siteMetaFor(group.lang, siteMeta), graph)
 		if err != nil {
 			return err
 		}
-		if d.IsDir() {
+		sources = append(sources, generated...)
+	}
+	paged, err := sb.paginate(sources)
+	if err != nil {
+		return err
+	}
+	sources = append(sources, paged...)
+	sb.linkTranslations(sources)
+	if err := checkOutputs(sources, sb.languageOutputs()); err != nil {
+		return err
+	}
+	if err := sb.renderPages(tmpl, sources); err != nil {
+		return err
+	}
+	if err := sb.writeSitemap(sources); err != nil {
+		return err
+	}
+	if err := sb.writeFeeds(tmpl, sources); err != nil {
+		return err
+	}
+	return sb.writeRedirects(sources)
+}
+
+// pageSource is a page file loaded from the pages directory, ready to render
+type pageSource struct {
+	path       string // source file path
+	treePath   string // output path of the page's place in the pages tree
+	body       string // content without front matter
+	isMarkdown bool
+	entry      cacheEntry
+	page       *domain.Page
+}
+
+// discoverPages returns the page files under the pages directory in walk order
+func (sb *SiteBuilder) discoverPages() ([]string, error) {
+	var paths []string
+	err := sb.fs.WalkDir(sb.site.PagesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isPageFile(d.Name()) {
 			return nil
 		}
-		if d.Name() == domain.IndexFile {
-			rel, _ := filepath.Rel(sb.site.PagesDir, path)
-			dst := filepath.Join(sb.site.DistDir, rel)
-			if err := sb.fs.MkdirAll(filepath.Dir(dst), 0755); err != nil {
-				return err
-			}
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
 
-			var title string
-			if rel == domain.IndexFile {
-				title = "Home"
-			} else {
-				dir := filepath.Dir(rel)
-				title = strings.Title(filepath.Base(dir))
-			}
+// loadPages reads the front matter of every page before any page is rendered,
+// expanding record templates into their record pages. Drafts, pages published
+// in the future and expired pages are left out and recorded as skipped. Every
+// page is attempted; the errors of failed pages are joined in discovery order.
+func (sb *SiteBuilder) loadPages(siteMeta meta.Meta) ([]*pageSource, error) {
+	sb.skipped = nil
+	paths, err := sb.discoverPages()
+	if err != nil {
+		return nil, err
+	}
 
-			content, err := sb.fs.ReadFile(path)
-			if err != nil {
-				return err
-			}
+	var sources []*pageSource
+	var errs []error
+	for _, path := range paths {
+		src, err := sb.loadPage(siteMeta, path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if reason := sb.unpublished(src.page); reason != "" {
+			sb.skipped = append(sb.skipped, skippedPage{path: path, reason: reason})
+			continue
+		}
 
-			// Parse front matter
-			pageMeta, body, err := meta.ParseFrontMatter(string(content))
+		// Record templates are replaced by a page per data record
+		if source := stringParam(src.page.Params, "data_source"); source != "" {
+			records, err := sb.recordPages(src, source)
 			if err != nil {
-				return err
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				continue
 			}
+			for _, record := range records {
+				if err := sb.applyPermalink(record); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				}
+			}
+			sources = append(sources, records...)
+			continue
+		}
+		if err := sb.applyPermalink(src); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		sources = append(sources, src)
+	}
+	return sources, errors.Join(errs...)
+}
 
-			// Merge meta
-			mergedMeta := meta.Merge(siteMeta, pageMeta)
+// loadPage reads a page file and parses its front matter. Markdown pages are
+// given an .html path, and pages of a language are written under its code.
+func (sb *SiteBuilder) loadPage(siteMeta meta.Meta, path string) (*pageSource, error) {
+	rel, _ := filepath.Rel(sb.site.PagesDir, path)
+	isMarkdown := filepath.Ext(rel) == domain.MarkdownExt
+	if isMarkdown {
+		rel = strings.TrimSuffix(rel, domain.MarkdownExt) + domain.PageExt
+	}
+	lang, langRel := sb.pageLanguage(rel)
+	rel = languagePath(lang, langRel)
+	siteMeta = sb.siteMetaFor(lang, siteMeta)
 
-			// Validate meta
-			if err := mergedMeta.Validate(sb.site.AssetsDir); err != nil {
-				return err
-			}
+	content, err := sb.fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
 
-			// Parse page content as template
-			pageTmpl, err := template.New("page").Parse(body)
-			if err != nil {
-				return err
-			}
+	// Parse front matter
+	pageMeta, params, body, err := meta.ParseFrontMatterParams(string(content))
+	if err != nil {
+		return nil, err
+	}
 
-			// Create page data without Content
-			pageData := domain.Page{
-				Title:  title,
-				Path:   rel,
-				IsDev:  sb.site.EnableAutoReload,
-				Config: sb.site.Config,
-				Meta:   mergedMeta,
-			}
+	// Merge meta
+	mergedMeta := meta.Merge(siteMeta, pageMeta)
 
-			// Execute page template
-			var buf bytes.Buffer
-			if err := pageTmpl.Execute(&buf, pageData); err != nil {
-				return err
-			}
+	// Validate meta
+	if err := mergedMeta.Validate(sb.site.AssetsDir); err != nil {
+		return nil, err
+	}
 
-			page := domain.Page{
-				Title:   title,
-				Content: template.HTML(buf.String()),
-				Path:    rel,
-				IsDev:   sb.site.EnableAutoReload,
-				Config:  sb.site.Config,
-				Meta:    mergedMeta,
-			}
+	date, err := timeParam(params, "date")
+	if err != nil {
+		return nil, err
+	}
+	lastmod, err := timeParam(params, "lastmod")
+	if err != nil {
+		return nil, err
+	}
+	if lastmod.IsZero() {
+		if info, err := sb.fs.Stat(path); err == nil {
+			lastmod = info.ModTime()
+		}
+	}
+	publishDate, err := timeParam(params, "publish_date")
+	if err != nil {
+		return nil, err
+	}
+	expiryDate, err := timeParam(params, "expiry_date")
+	if err != nil {
+		return nil, err
+	}
 
-			f, err := sb.fs.Create(dst)
-			if err != nil {
-				return err
+	return &pageSource{
+		path:       path,
+		treePath:   rel,
+		body:       body,
+		isMarkdown: isMarkdown,
+		entry: cacheEntry{
+			Source:        path,
+			SourceHash:    hashBytes(content),
+			TemplatesHash: sb.templatesHash,
+			ConfigHash:    sb.configHash,
+		},
+		page: &domain.Page{
+			Title:    pageTitle(langRel),
+			Path:     rel,
+			URL:      pageURL(rel),
+			IsDev:    sb.site.EnableAutoReload,
+			Config:   sb.site.Config,
+			Data:     sb.data,
+			Meta:     mergedMeta,
+			Params:   params,
+			Layout:   stringParam(params, "layout"),
+			Section:  pageSection(langRel),
+			Date:     date,
+			Lastmod:  lastmod,
+			Weight:   intParam(params, "weight"),
+			Language: lang,
+
+			Draft:       params["draft"] == true,
+			PublishDate: publishDate,
+			ExpiryDate:  expiryDate,
+		},
+	}, nil
+}
+
+// renderPages renders pages on a bounded pool of workers. Every page is
+// attempted; the errors of failed pages are joined in discovery order.
+func (sb *SiteBuilder) renderPages(tmpl *template.Template, sources []*pageSource) error {
+	jobs := sb.site.Jobs
+	if jobs < 1 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+
+	errs := make([]error, len(sources))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs && w < len(sources); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := sb.renderPage(tmpl, sources[i]); err != nil {
+					errs[i] = fmt.Errorf("%s: %w", sources[i].path, err)
+				}
 			}
-			defer f.Close()
-			return tmpl.ExecuteTemplate(f, domain.BaseTemplate, page)
+		}()
+	}
+	for i := range sources {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// renderPage renders a single page into the output directory, keeping its
+// path relative to the pages directory. The shared page is copied so that
+// pages rendered in parallel never see each other's content.
+func (sb *SiteBuilder) renderPage(tmpl *template.Template, src *pageSource) error {
+	page := *src.page
+	dst := filepath.Join(sb.outDir, page.Path)
+	if err := sb.fs.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	// Skip pages whose output is up to date
+	if reused, err := sb.reuse(page.Path, src.entry); err != nil || reused {
+		return err
+	}
+
+	// Resolve layout
+	layoutName, err := sb.layoutTemplate(tmpl, page.Layout)
+	if err != nil {
+		return err
+	}
+
+	page.Content, err = sb.pageContent(tmpl, src, &page)
+	if err != nil {
+		return err
+	}
+
+	w, err := sb.createOutput(dst, &page)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.ExecuteTemplate(w, layoutName, page); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	sb.cache.record(page.Path, src.entry)
+	return nil
+}
+
+// pageContent renders the body of a page without its layout. Markdown bodies
+// are converted to HTML; HTML bodies are executed as templates with the page
+// as data. Shortcodes in either are rendered with the templates in tmpl.
+func (sb *SiteBuilder) pageContent(tmpl *template.Template, src *pageSource, page *domain.Page) (template.HTML, error) {
+	body, shortcodes, err := sb.renderShortcodes(tmpl, src.body, page, src.isMarkdown)
+	if err != nil {
+		return "", err
+	}
+
+	if src.isMarkdown {
+		// Convert Markdown body to HTML
+		html, err := sb.markdown.Convert([]byte(body))
+		if err != nil {
+			return "", err
 		}
-		return nil
-	})
+		return template.HTML(restoreShortcodes(string(html), shortcodes)), nil
+	}
+
+	// Parse page content as template
+	pageTmpl, err := template.New("page").Funcs(template.FuncMap{"asset": sb.assetURL}).Parse(body)
+	if err != nil {
+		return "", err
+	}
+
+	// Execute page template
+	var buf bytes.Buffer
+	if err := pageTmpl.Execute(&buf, page); err != nil {
+		return "", err
+	}
+	return template.HTML(restoreShortcodes(buf.String(), shortcodes)), nil
+}
+
+// isPageFile reports whether a file in the pages directory should be rendered
+func isPageFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == domain.PageExt || ext == domain.MarkdownExt
+}
+
+// pageTitle derives a default title from a page path relative to the pages
+// directory. Index files take the name of their directory, other files the
+// name of the file without its extension.
+func pageTitle(rel string) string {
+	if rel == domain.IndexFile {
+		return "Home"
+	}
+	if filepath.Base(rel) == domain.IndexFile {
+		return strings.Title(filepath.Base(filepath.Dir(rel)))
+	}
+	return strings.Title(strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel)))
+}
+
+// pageURL returns the URL path of a page relative to the pages directory.
+// Index files are served at their directory, such as /blog/.
+func pageURL(rel string) string {
+	url := "/" + filepath.ToSlash(rel)
+	if filepath.Base(rel) == domain.IndexFile {
+		return strings.TrimSuffix(url, domain.IndexFile)
+	}
+	return url
 }
 
 func (sb *SiteBuilder) copyAssets() error {
 	src := sb.site.AssetsDir
-	dst := filepath.Join(sb.site.DistDir, "assets")
+	dst := filepath.Join(sb.outDir, "assets")
 	return sb.fs.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		rel, _ := filepath.Rel(src, path)
-		target := filepath.Join(dst, rel)
 		if d.IsDir() {
-			return sb.fs.MkdirAll(target, 0755)
+			return sb.fs.MkdirAll(filepath.Join(dst, rel), 0755)
 		}
-		return sb.copyFile(path, target)
+
+		content, ok := sb.assetContent[filepath.ToSlash(rel)]
+		if !ok {
+			var err error
+			if content, err = sb.fs.ReadFile(path); err != nil {
+				return err
+			}
+			if content, err = sb.minifyAsset(rel, content); err != nil {
+				return err
+			}
+		}
+		target := filepath.Join(dst, sb.assetOutput(rel))
+		output := filepath.Join("assets", sb.assetOutput(rel))
+		entry := cacheEntry{Source: path, SourceHash: hashBytes(content)}
+		if reused, err := sb.reuse(output, entry); err != nil || reused {
+			return err
+		}
+		if err := sb.writeFile(target, content); err != nil {
+			return err
+		}
+		sb.cache.record(output, entry)
+		return nil
 	})
 }
 
@@ -168,6 +652,10 @@ func (sb *SiteBuilder) copyFile(src, dst string) error {
 	if err != nil {
 		return err
 	}
+	return sb.writeFile(dst, content)
+}
+
+func (sb *SiteBuilder) writeFile(dst string, content []byte) error {
 	f, err := sb.fs.Create(dst)
 	if err != nil {
 		return err
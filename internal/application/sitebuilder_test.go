@@ -7,8 +7,11 @@ import (
 	"io"
 	"io/fs"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/EmiraLabs/stw-cli/internal/domain"
 	"github.com/EmiraLabs/stw-cli/internal/meta"
@@ -17,25 +20,32 @@ import (
 // MockFileSystem is a mock implementation of FileSystem for testing
 type MockFileSystem struct {
 	files       map[string][]byte
+	written     map[string]*bytes.Buffer
 	dirs        map[string]bool
 	walkCalls   []string
 	createCalls []string
 	mkdirCalls  []string
 	removeCalls []string
+	renameCalls []string
+	linkCalls   []string
 	readError   error
 	createError error
+	linkError   error
 	mkdirError  error
 	removeError error
+	mu          sync.Mutex
 }
 
 func NewMockFileSystem() *MockFileSystem {
 	return &MockFileSystem{
 		files:       make(map[string][]byte),
+		written:     make(map[string]*bytes.Buffer),
 		dirs:        make(map[string]bool),
 		walkCalls:   []string{},
 		createCalls: []string{},
 		mkdirCalls:  []string{},
 		removeCalls: []string{},
+		renameCalls: []string{},
 	}
 }
 
@@ -55,6 +65,17 @@ func (m *MockFileSystem) WalkDir(root string, fn fs.WalkDirFunc) error {
 		if err := fn("pages/about/contact/index.html", &mockDirEntry{name: domain.IndexFile, isDir: false}, nil); err != nil {
 			return err
 		}
+		// standalone pages
+		if err := fn("pages/404.html", &mockDirEntry{name: "404.html", isDir: false}, nil); err != nil {
+			return err
+		}
+		if err := fn("pages/blog/my-post.html", &mockDirEntry{name: "my-post.html", isDir: false}, nil); err != nil {
+			return err
+		}
+		// markdown page
+		if err := fn("pages/blog/hello.md", &mockDirEntry{name: "hello.md", isDir: false}, nil); err != nil {
+			return err
+		}
 		// some other file
 		if err := fn("pages/other.txt", &mockDirEntry{name: "other.txt", isDir: false}, nil); err != nil {
 			return err
@@ -76,17 +97,39 @@ func (m *MockFileSystem) WalkDir(root string, fn fs.WalkDirFunc) error {
 		if err := fn("assets/js/app.js", &mockDirEntry{name: "app.js", isDir: false}, nil); err != nil {
 			return err
 		}
+		return nil
+	}
+	if root == "pages" {
+		return nil
+	}
+	// Walk the mock files under any other root in sorted order
+	var names []string
+	for name := range m.files {
+		if inPath(name, root) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := fn(name, &mockDirEntry{name: filepath.Base(name), isDir: false}, nil); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 func (m *MockFileSystem) ReadFile(filename string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.readError != nil {
 		return nil, m.readError
 	}
 	if content, ok := m.files[filename]; ok {
 		return content, nil
 	}
+	if buf, ok := m.written[filename]; ok {
+		return buf.Bytes(), nil
+	}
 	// Default content for pages
 	if strings.HasSuffix(filename, domain.IndexFile) {
 		return []byte("<h1>{{.Title}}</h1>{{.Config.test}}"), nil
@@ -95,14 +138,20 @@ func (m *MockFileSystem) ReadFile(filename string) ([]byte, error) {
 }
 
 func (m *MockFileSystem) Create(filename string) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.createCalls = append(m.createCalls, filename)
 	if m.createError != nil {
 		return nil, m.createError
 	}
-	return &mockWriteCloser{buffer: &bytes.Buffer{}}, nil
+	buf := &bytes.Buffer{}
+	m.written[filename] = buf
+	return &mockWriteCloser{buffer: buf}, nil
 }
 
 func (m *MockFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.mkdirCalls = append(m.mkdirCalls, path)
 	if m.mkdirError != nil {
 		return m.mkdirError
@@ -112,13 +161,85 @@ func (m *MockFileSystem) MkdirAll(path string, perm fs.FileMode) error {
 }
 
 func (m *MockFileSystem) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.removeCalls = append(m.removeCalls, path)
 	if m.removeError != nil {
 		return m.removeError
 	}
+	for name := range m.written {
+		if inPath(name, path) {
+			delete(m.written, name)
+		}
+	}
+	for name := range m.dirs {
+		if inPath(name, path) {
+			delete(m.dirs, name)
+		}
+	}
+	return nil
+}
+
+// Rename moves written files and directories under oldpath to newpath
+func (m *MockFileSystem) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.renameCalls = append(m.renameCalls, oldpath+" -> "+newpath)
+	for name, buf := range m.written {
+		if inPath(name, oldpath) {
+			delete(m.written, name)
+			m.written[newpath+strings.TrimPrefix(name, oldpath)] = buf
+		}
+	}
+	for name := range m.dirs {
+		if inPath(name, oldpath) {
+			delete(m.dirs, name)
+			m.dirs[newpath+strings.TrimPrefix(name, oldpath)] = true
+		}
+	}
 	return nil
 }
 
+// Link shares the written buffer or file of oldname with newname, like a hard
+// link
+func (m *MockFileSystem) Link(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.linkCalls = append(m.linkCalls, oldname+" -> "+newname)
+	if m.linkError != nil {
+		return m.linkError
+	}
+	if buf, ok := m.written[oldname]; ok {
+		m.written[newname] = buf
+		return nil
+	}
+	if content, ok := m.files[oldname]; ok {
+		m.files[newname] = content
+		return nil
+	}
+	return fs.ErrNotExist
+}
+
+// inPath reports whether name is path or lies below it
+func inPath(name, path string) bool {
+	return name == path || strings.HasPrefix(name, path+"/")
+}
+
+func (m *MockFileSystem) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[name]; ok {
+		return &mockFileInfo{name: filepath.Base(name)}, nil
+	}
+	if _, ok := m.written[name]; ok {
+		return &mockFileInfo{name: filepath.Base(name)}, nil
+	}
+	if m.dirs[name] {
+		return &mockFileInfo{name: filepath.Base(name), isDir: true}, nil
+	}
+	return nil, fs.ErrNotExist
+}
+
 type mockDirEntry struct {
 	name  string
 	isDir bool
@@ -129,6 +250,21 @@ func (m *mockDirEntry) IsDir() bool                { return m.isDir }
 func (m *mockDirEntry) Type() fs.FileMode          { return 0 }
 func (m *mockDirEntry) Info() (fs.FileInfo, error) { return nil, nil }
 
+// mockModTime is the modification time of every file in MockFileSystem
+var mockModTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+type mockFileInfo struct {
+	name  string
+	isDir bool
+}
+
+func (m *mockFileInfo) Name() string       { return m.name }
+func (m *mockFileInfo) Size() int64        { return 0 }
+func (m *mockFileInfo) Mode() fs.FileMode  { return 0644 }
+func (m *mockFileInfo) ModTime() time.Time { return mockModTime }
+func (m *mockFileInfo) IsDir() bool        { return m.isDir }
+func (m *mockFileInfo) Sys() interface{}   { return nil }
+
 type mockWriteCloser struct {
 	buffer *bytes.Buffer
 }
@@ -144,6 +280,7 @@ func (m *mockWriteCloser) Close() error {
 // MockTemplateRenderer is a mock implementation of TemplateRenderer
 type MockTemplateRenderer struct {
 	parseFilesCalls [][]string
+	parseTmplCalls  [][]string
 	executeCalls    []executeCall
 	tmpl            *template.Template
 	parseError      error
@@ -158,6 +295,7 @@ type executeCall struct {
 func NewMockTemplateRenderer() *MockTemplateRenderer {
 	return &MockTemplateRenderer{
 		parseFilesCalls: [][]string{},
+		parseTmplCalls:  [][]string{},
 		executeCalls:    []executeCall{},
 	}
 }
@@ -179,6 +317,11 @@ func (m *MockTemplateRenderer) ParseFiles(filenames ...string) (*template.Templa
 	return tmpl, nil
 }
 
+func (m *MockTemplateRenderer) ParseTemplates(root string, filenames ...string) (*template.Template, error) {
+	m.parseTmplCalls = append(m.parseTmplCalls, filenames)
+	return m.ParseFiles(filenames...)
+}
+
 func (m *MockTemplateRenderer) ExecuteTemplate(wr io.Writer, name string, data interface{}) error {
 	m.executeCalls = append(m.executeCalls, executeCall{name: name, data: data})
 	if m.executeError != nil {
@@ -251,58 +394,234 @@ func TestSiteBuilder_Build(t *testing.T) {
 		t.Errorf("Build failed: %v", err)
 	}
 
-	// Check that RemoveAll was called
-	if len(fs.removeCalls) != 1 || fs.removeCalls[0] != "dist" {
-		t.Error("RemoveAll not called correctly")
+	// Check that the staging dir was cleaned and created
+	if len(fs.removeCalls) < 1 || fs.removeCalls[0] != ".dist-staging" {
+		t.Error("RemoveAll for staging dir not called")
+	}
+	if len(fs.mkdirCalls) < 1 || fs.mkdirCalls[0] != ".dist-staging" {
+		t.Error("MkdirAll for staging dir not called")
 	}
 
-	// Check MkdirAll for dist
-	if len(fs.mkdirCalls) < 1 || fs.mkdirCalls[0] != "dist" {
-		t.Error("MkdirAll for dist not called")
+	// Check that staging was swapped into dist
+	if len(fs.renameCalls) != 1 || fs.renameCalls[0] != ".dist-staging -> dist" {
+		t.Errorf("Expected staging dir to be renamed to dist, got %v", fs.renameCalls)
 	}
 
-	// Check ParseFiles
-	if len(renderer.parseFilesCalls) != 1 {
-		t.Error("ParseFiles not called")
+	// Check ParseTemplates
+	if len(renderer.parseTmplCalls) != 1 {
+		t.Error("ParseTemplates not called")
 	}
 
 	// Check that pages were built
-	// Should have created dist/index.html, dist/about/index.html, etc.
-	expectedCreates := []string{"dist/index.html", "dist/about/index.html", "dist/about/contact/index.html"}
-	for _, expected := range expectedCreates {
-		found := false
-		for _, call := range fs.createCalls {
-			if call == expected {
-				found = true
-				break
-			}
-		}
-		if !found {
-			t.Errorf("Expected create call for %s", expected)
+	// Should have written dist/index.html, dist/about/index.html, etc.
+	expectedFiles := []string{"dist/index.html", "dist/about/index.html", "dist/about/contact/index.html", "dist/404.html", "dist/blog/my-post.html"}
+	for _, expected := range expectedFiles {
+		if _, ok := fs.written[expected]; !ok {
+			t.Errorf("Expected %s to be written", expected)
 		}
 	}
 
 	// Check assets copied
-	expectedAssetCreates := []string{"dist/assets/css/style.css", "dist/assets/js/app.js"}
-	for _, expected := range expectedAssetCreates {
-		found := false
-		for _, call := range fs.createCalls {
-			if call == expected {
-				found = true
-				break
+	expectedAssets := []string{"dist/assets/css/style.css", "dist/assets/js/app.js"}
+	for _, expected := range expectedAssets {
+		if _, ok := fs.written[expected]; !ok {
+			t.Errorf("Expected %s to be written", expected)
+		}
+	}
+}
+
+func TestSiteBuilder_Build_KeepsDistOnFailure(t *testing.T) {
+	site := &domain.Site{
+		PagesDir:     "pages",
+		TemplatesDir: "templates",
+		AssetsDir:    "assets",
+		DistDir:      "dist",
+		Config:       map[string]interface{}{},
+	}
+	fs := NewMockFileSystem()
+	fs.written["dist/index.html"] = bytes.NewBufferString("last good")
+	fs.dirs["dist"] = true
+	fs.files["pages/about/index.html"] = []byte("{{.Broken")
+	renderer := NewMockTemplateRenderer()
+	builder := NewSiteBuilder(site, fs, renderer)
+
+	if err := builder.Build(); err == nil {
+		t.Fatal("Expected build error")
+	}
+	if len(fs.renameCalls) != 0 {
+		t.Errorf("Failed build should not swap dist, got %v", fs.renameCalls)
+	}
+	if out, ok := fs.written["dist/index.html"]; !ok || out.String() != "last good" {
+		t.Error("Failed build should leave the last good output in place")
+	}
+	for name := range fs.written {
+		if inPath(name, ".dist-staging") {
+			t.Errorf("Staging output %s should be removed", name)
+		}
+	}
+}
+
+func TestSiteBuilder_Build_Incremental(t *testing.T) {
+	site := &domain.Site{
+		PagesDir:     "pages",
+		TemplatesDir: "templates",
+		AssetsDir:    "assets",
+		DistDir:      "dist",
+		CacheDir:     ".stw",
+		Config:       map[string]interface{}{"test": "value"},
+	}
+	fs := NewMockFileSystem()
+	renderer := NewMockTemplateRenderer()
+	builder := NewSiteBuilder(site, fs, renderer)
+
+	if err := builder.Build(); err != nil {
+		t.Fatalf("First build failed: %v", err)
+	}
+	if _, ok := fs.written[".stw/cache.json"]; !ok {
+		t.Fatal("Expected cache manifest to be written")
+	}
+
+	// Mark the live outputs so reused outputs can be told apart from rebuilt ones
+	mark := func() {
+		for name, buf := range fs.written {
+			if inPath(name, "dist") {
+				buf.Reset()
+				buf.WriteString("reused")
 			}
 		}
-		if !found {
-			t.Errorf("Expected create call for %s", expected)
+	}
+	reused := func(name string) bool {
+		buf, ok := fs.written[name]
+		return ok && buf.String() == "reused"
+	}
+
+	// Second build with unchanged inputs reuses every output
+	mark()
+	fs.createCalls = nil
+	if err := builder.Build(); err != nil {
+		t.Fatalf("Second build failed: %v", err)
+	}
+	for _, name := range []string{"dist/index.html", "dist/about/index.html", "dist/assets/css/style.css"} {
+		if !reused(name) {
+			t.Errorf("Expected %s to be reused", name)
+		}
+	}
+	for _, name := range fs.createCalls {
+		if name == ".dist-staging/index.html" {
+			t.Error("Expected reused outputs to be linked instead of written")
 		}
 	}
+
+	// Changing a page rebuilds only that page and the list page of its section
+	fs.files["pages/about/contact/index.html"] = []byte("<h1>Changed</h1>")
+	mark()
+	if err := builder.Build(); err != nil {
+		t.Fatalf("Third build failed: %v", err)
+	}
+	if reused("dist/about/contact/index.html") || reused("dist/about/index.html") || !reused("dist/index.html") {
+		t.Error("Expected only the changed page and its list page to be rebuilt")
+	}
+
+	// Changing the config rebuilds every page but no assets
+	site.Config = map[string]interface{}{"test": "other"}
+	mark()
+	if err := builder.Build(); err != nil {
+		t.Fatalf("Fourth build failed: %v", err)
+	}
+	if reused("dist/index.html") || !reused("dist/assets/css/style.css") {
+		t.Error("Expected pages but not assets to be rebuilt")
+	}
+}
+
+func TestSiteBuilder_Build_ReuseWithoutLinks(t *testing.T) {
+	site := &domain.Site{
+		PagesDir:     "pages",
+		TemplatesDir: "templates",
+		AssetsDir:    "assets",
+		DistDir:      "dist",
+		CacheDir:     ".stw",
+	}
+	fs := NewMockFileSystem()
+	builder := NewSiteBuilder(site, fs, NewMockTemplateRenderer())
+
+	if err := builder.Build(); err != nil {
+		t.Fatalf("First build failed: %v", err)
+	}
+	fs.written["dist/index.html"].Reset()
+	fs.written["dist/index.html"].WriteString("reused")
+
+	// File systems without hard links get a copy of the output
+	fs.linkError = errors.New("links not supported")
+	if err := builder.Build(); err != nil {
+		t.Fatalf("Second build failed: %v", err)
+	}
+	if got := fs.written["dist/index.html"].String(); got != "reused" {
+		t.Errorf("Expected the output to be copied, got %q", got)
+	}
+}
+
+func TestSiteBuilder_Build_AutoReloadRebuilds(t *testing.T) {
+	site := &domain.Site{
+		PagesDir:         "pages",
+		TemplatesDir:     "templates",
+		AssetsDir:        "assets",
+		DistDir:          "dist",
+		CacheDir:         ".stw",
+		EnableAutoReload: true,
+	}
+	fs := NewMockFileSystem()
+	builder := NewSiteBuilder(site, fs, NewMockTemplateRenderer())
+
+	if err := builder.Build(); err != nil {
+		t.Fatalf("Serve build failed: %v", err)
+	}
+	fs.written["dist/index.html"].Reset()
+	fs.written["dist/index.html"].WriteString("dev")
+
+	// A production build after stw serve must not reuse the pages rendered for it
+	site.EnableAutoReload = false
+	if err := builder.Build(); err != nil {
+		t.Fatalf("Production build failed: %v", err)
+	}
+	if got := fs.written["dist/index.html"].String(); got == "dev" {
+		t.Error("Expected the page to be re-rendered when auto reload is turned off")
+	}
+	fs.written["dist/index.html"].Reset()
+	fs.written["dist/index.html"].WriteString("prod")
+
+	site.EnableAutoReload = true
+	if err := builder.Build(); err != nil {
+		t.Fatalf("Second serve build failed: %v", err)
+	}
+	if got := fs.written["dist/index.html"].String(); got == "prod" {
+		t.Error("Expected the page to be re-rendered when auto reload is turned on")
+	}
+}
+
+// renderTestPages loads and renders the given page files
+func renderTestPages(builder *SiteBuilder, tmpl *template.Template, paths ...string) error {
+	var sources []*pageSource
+	for _, path := range paths {
+		src, err := builder.loadPage(meta.Meta{}, path)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, src)
+	}
+	return builder.renderPages(tmpl, sources)
+}
+
+func newTestBuilder(site *domain.Site, fs *MockFileSystem, renderer *MockTemplateRenderer) *SiteBuilder {
+	builder := NewSiteBuilder(site, fs, renderer)
+	builder.outDir = site.DistDir
+	return builder
 }
 
 func TestSiteBuilder_buildPages(t *testing.T) {
 	site := &domain.Site{DistDir: "dist", PagesDir: "pages", Config: map[string]interface{}{"test": "value"}}
 	fs := NewMockFileSystem()
 	renderer := NewMockTemplateRenderer()
-	builder := &SiteBuilder{site: site, fs: fs, renderer: renderer}
+	builder := newTestBuilder(site, fs, renderer)
 	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
 
 	err := builder.buildPages(tmpl, meta.Meta{})
@@ -324,6 +643,158 @@ func TestSiteBuilder_buildPages(t *testing.T) {
 			t.Errorf("Expected mkdir for %s", expected)
 		}
 	}
+
+	// Markdown pages are written as .html
+	if !contains(fs.createCalls, "dist/blog/hello.html") {
+		t.Error("Expected create call for dist/blog/hello.html")
+	}
+
+	// Only page files are rendered
+	if contains(fs.createCalls, "dist/other.txt") {
+		t.Error("Non-HTML page file should not be rendered")
+	}
+}
+
+func TestSiteBuilder_renderPage_Markdown(t *testing.T) {
+	site := &domain.Site{DistDir: "dist", PagesDir: "pages", Config: map[string]interface{}{}}
+	fs := NewMockFileSystem()
+	fs.files["pages/blog/post.md"] = []byte("---\ntitle: \"Post\"\n---\n# Hello {{.Title}}\n\n| a | b |\n|---|---|\n| 1 | 2 |\n")
+	renderer := NewMockTemplateRenderer()
+	builder := newTestBuilder(site, fs, renderer)
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+	if err := renderTestPages(builder, tmpl, "pages/blog/post.md"); err != nil {
+		t.Fatalf("renderPages failed: %v", err)
+	}
+	if !contains(fs.createCalls, "dist/blog/post.html") {
+		t.Error("Expected create call for dist/blog/post.html")
+	}
+	out := fs.written["dist/blog/post.html"].String()
+	for _, expected := range []string{`<h1 id="hello-title">Hello {{.Title}}</h1>`, "<table>"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected output to contain %q, got %s", expected, out)
+		}
+	}
+}
+
+func TestSiteBuilder_renderPage_Layout(t *testing.T) {
+	site := &domain.Site{DistDir: "dist", PagesDir: "pages", TemplatesDir: "templates", Config: map[string]interface{}{}}
+	fs := NewMockFileSystem()
+	fs.files["pages/guide.html"] = []byte("---\nlayout: docs\n---\n<p>Guide</p>")
+	fs.files["pages/missing.html"] = []byte("---\nlayout: landing\n---\n<p>Missing</p>")
+	renderer := NewMockTemplateRenderer()
+	builder := newTestBuilder(site, fs, renderer)
+	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+	template.Must(tmpl.New("layouts/docs.html").Parse("<div class=\"docs\">{{.Content}}</div>"))
+
+	if err := renderTestPages(builder, tmpl, "pages/guide.html"); err != nil {
+		t.Fatalf("renderPages failed: %v", err)
+	}
+	if out := fs.written["dist/guide.html"].String(); out != "<div class=\"docs\"><p>Guide</p></div>" {
+		t.Errorf("Expected page rendered with docs layout, got %q", out)
+	}
+
+	err := renderTestPages(builder, tmpl, "pages/missing.html")
+	if err == nil {
+		t.Fatal("Expected error for missing layout")
+	}
+	if !strings.Contains(err.Error(), "pages/missing.html") || !strings.Contains(err.Error(), `layout "landing" not found`) {
+		t.Errorf("Expected error naming page and layout, got %q", err)
+	}
+}
+
+func TestPageTitle(t *testing.T) {
+	tests := map[string]string{
+		"index.html":               "Home",
+		"about/index.html":         "About",
+		"about/contact/index.html": "Contact",
+		"404.html":                 "404",
+		"blog/my-post.html":        "My-Post",
+		"blog/index.html":          "Blog",
+	}
+	for rel, expected := range tests {
+		if got := pageTitle(rel); got != expected {
+			t.Errorf("pageTitle(%q) = %q, expected %q", rel, got, expected)
+		}
+	}
+}
+
+func TestSiteBuilder_discoverTemplates(t *testing.T) {
+	site := &domain.Site{TemplatesDir: "templates"}
+	fs := NewMockFileSystem()
+	fs.files["templates/base.html"] = []byte("")
+	fs.files["templates/components/sidebar.html"] = []byte("")
+	fs.files["templates/partials/scripts.html"] = []byte("")
+	fs.files["templates/README.md"] = []byte("")
+	builder := NewSiteBuilder(site, fs, NewMockTemplateRenderer())
+
+	files, err := builder.discoverTemplates()
+	if err != nil {
+		t.Fatalf("discoverTemplates failed: %v", err)
+	}
+	expected := []string{"templates/base.html", "templates/components/sidebar.html", "templates/partials/scripts.html"}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("discoverTemplates() = %v, expected %v", files, expected)
+	}
+}
+
+func TestSiteBuilder_layoutTemplate_MissingBase(t *testing.T) {
+	site := &domain.Site{TemplatesDir: "templates"}
+	builder := NewSiteBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+	tmpl := template.Must(template.New("layouts/docs.html").Parse(""))
+
+	if _, err := builder.layoutTemplate(tmpl, ""); err == nil || !strings.Contains(err.Error(), "templates/base.html") {
+		t.Errorf("Expected error naming base.html, got %v", err)
+	}
+	if name, err := builder.layoutTemplate(tmpl, "docs"); err != nil || name != "layouts/docs.html" {
+		t.Errorf("layoutTemplate(docs) = %q, %v", name, err)
+	}
+}
+
+func TestSiteBuilder_discoverPages(t *testing.T) {
+	site := &domain.Site{PagesDir: "pages"}
+	builder := NewSiteBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	paths, err := builder.discoverPages()
+	if err != nil {
+		t.Fatalf("discoverPages failed: %v", err)
+	}
+	expected := []string{
+		"pages/index.html",
+		"pages/about/index.html",
+		"pages/about/contact/index.html",
+		"pages/404.html",
+		"pages/blog/my-post.html",
+		"pages/blog/hello.md",
+	}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("discoverPages() = %v, expected %v", paths, expected)
+	}
+}
+
+func TestSiteBuilder_renderPages_ReportsEveryPage(t *testing.T) {
+	for _, jobs := range []int{1, 4} {
+		site := &domain.Site{DistDir: "dist", PagesDir: "pages", Jobs: jobs, Config: map[string]interface{}{}}
+		fs := NewMockFileSystem()
+		fs.files["pages/a.html"] = []byte("{{.Missing")
+		fs.files["pages/b.html"] = []byte("<p>ok</p>")
+		fs.files["pages/c.html"] = []byte("{{.Broken")
+		renderer := NewMockTemplateRenderer()
+		builder := newTestBuilder(site, fs, renderer)
+		tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
+
+		err := renderTestPages(builder, tmpl, "pages/a.html", "pages/b.html", "pages/c.html")
+		if err == nil {
+			t.Fatalf("jobs=%d: expected error", jobs)
+		}
+		msg := err.Error()
+		if !strings.HasPrefix(msg, "pages/a.html: ") || !strings.Contains(msg, "\npages/c.html: ") {
+			t.Errorf("jobs=%d: expected per-page errors in order, got %q", jobs, msg)
+		}
+		if !contains(fs.createCalls, "dist/b.html") {
+			t.Errorf("jobs=%d: expected valid page to be rendered", jobs)
+		}
+	}
 }
 
 func TestSiteBuilder_buildPages_MkdirError(t *testing.T) {
@@ -331,7 +802,7 @@ func TestSiteBuilder_buildPages_MkdirError(t *testing.T) {
 	fs := NewMockFileSystem()
 	fs.mkdirError = errors.New("mkdir error")
 	renderer := NewMockTemplateRenderer()
-	builder := &SiteBuilder{site: site, fs: fs, renderer: renderer}
+	builder := newTestBuilder(site, fs, renderer)
 	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
 
 	err := builder.buildPages(tmpl, meta.Meta{})
@@ -345,7 +816,7 @@ func TestSiteBuilder_buildPages_ExecuteError(t *testing.T) {
 	fs := NewMockFileSystem()
 	renderer := NewMockTemplateRenderer()
 	renderer.executeError = errors.New("execute error")
-	builder := &SiteBuilder{site: site, fs: fs, renderer: renderer}
+	builder := newTestBuilder(site, fs, renderer)
 	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
 
 	err := builder.buildPages(tmpl, meta.Meta{})
@@ -359,7 +830,7 @@ func TestSiteBuilder_buildPages_ReadError(t *testing.T) {
 	fs := NewMockFileSystem()
 	fs.readError = errors.New("read error")
 	renderer := NewMockTemplateRenderer()
-	builder := &SiteBuilder{site: site, fs: fs, renderer: renderer}
+	builder := newTestBuilder(site, fs, renderer)
 	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
 
 	err := builder.buildPages(tmpl, meta.Meta{})
@@ -373,7 +844,7 @@ func TestSiteBuilder_buildPages_CreateError(t *testing.T) {
 	fs := NewMockFileSystem()
 	fs.createError = errors.New("create error")
 	renderer := NewMockTemplateRenderer()
-	builder := &SiteBuilder{site: site, fs: fs, renderer: renderer}
+	builder := newTestBuilder(site, fs, renderer)
 	tmpl, _ := renderer.ParseFiles(filepath.Join("templates", domain.BaseTemplate))
 
 	err := builder.buildPages(tmpl, meta.Meta{})
@@ -385,7 +856,7 @@ func TestSiteBuilder_buildPages_CreateError(t *testing.T) {
 func TestSiteBuilder_copyAssets(t *testing.T) {
 	site := &domain.Site{DistDir: "dist", AssetsDir: "assets"}
 	fs := NewMockFileSystem()
-	builder := &SiteBuilder{site: site, fs: fs}
+	builder := &SiteBuilder{site: site, fs: fs, outDir: site.DistDir}
 
 	err := builder.copyAssets()
 	if err != nil {
@@ -408,7 +879,7 @@ func TestSiteBuilder_copyAssets_Error(t *testing.T) {
 	site := &domain.Site{DistDir: "dist", AssetsDir: "assets"}
 	fs := NewMockFileSystem()
 	fs.createError = errors.New("create error")
-	builder := &SiteBuilder{site: site, fs: fs}
+	builder := &SiteBuilder{site: site, fs: fs, outDir: site.DistDir}
 
 	err := builder.copyAssets()
 	if err == nil {
@@ -0,0 +1,103 @@
+package application
+
+import (
+	"encoding/json"
+	"html/template"
+	"regexp"
+	"strconv"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+var (
+	// siteGraphRef matches references to page data built from the front
+	// matter of every page: the site graph, the taxonomies and the terms of
+	// a page
+	siteGraphRef = regexp.MustCompile(`\.(Site|Taxonomies|Taxonomy|Terms)\b`)
+	// templateCall matches a call of another template, such as
+	// {{template "partials/nav.html" .}}
+	templateCall = regexp.MustCompile(`\{\{template "((?:[^"\\]|\\.)*)"`)
+)
+
+// linkSite gives every page the site graph of all pages, which on multilingual
+// sites are the pages of one language. The front matter of all pages is hashed
+// into the pages hash, and pages that can read the graph are rebuilt when it
+// changes. Other pages, and edits to a page's body alone, only rebuild that
+// page.
+func (sb *SiteBuilder) linkSite(sources []*pageSource) *domain.SiteGraph {
+	pages := make([]*domain.Page, 0, len(sources))
+	var frontMatter []byte
+	for _, src := range sources {
+		pages = append(pages, src.page)
+
+		params, err := json.Marshal([]interface{}{src.page.Params, src.page.Record})
+		if err != nil {
+			params = []byte(src.entry.SourceHash)
+		}
+		frontMatter = append(frontMatter, src.page.Path...)
+		frontMatter = append(frontMatter, hashBytes(params)...)
+	}
+	sortPages(pages, "")
+	sb.pagesHash = hashBytes(frontMatter)
+
+	graph := domain.NewSiteGraph(pages)
+	graph.Languages = sb.languages
+	for _, src := range sources {
+		src.page.Site = graph
+		if sb.readsSiteGraph(src.body, src.page.Layout) {
+			src.entry.DepsHash = hashBytes([]byte(src.entry.DepsHash + "\n" + sb.pagesHash))
+		}
+	}
+	return graph
+}
+
+// readsSiteGraph reports whether a page with the given body and layout can
+// read the site graph: its body refers to it, or its layout or one of the
+// shortcodes it calls does
+func (sb *SiteBuilder) readsSiteGraph(body, layout string) bool {
+	if siteGraphRef.MatchString(body) || sb.siteTemplates[layoutName(layout)] {
+		return true
+	}
+	for _, call := range shortcodeTag.FindAllStringSubmatch(body, -1) {
+		if sb.siteTemplates[domain.ShortcodesDir+"/"+call[2]+domain.PageExt] {
+			return true
+		}
+	}
+	return false
+}
+
+// siteTemplates returns the names of the templates that read the site graph,
+// themselves or through the templates they call
+func siteTemplates(tmpl *template.Template) map[string]bool {
+	reads := make(map[string]bool)
+	calls := make(map[string][]string)
+	if tmpl == nil {
+		return reads
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		source := t.Tree.Root.String()
+		reads[t.Name()] = siteGraphRef.MatchString(source)
+		for _, call := range templateCall.FindAllStringSubmatch(source, -1) {
+			if name, err := strconv.Unquote(`"` + call[1] + `"`); err == nil {
+				calls[t.Name()] = append(calls[t.Name()], name)
+			}
+		}
+	}
+
+	// Spread reads to the callers until nothing changes
+	for changed := true; changed; {
+		changed = false
+		for name, called := range calls {
+			for _, callee := range called {
+				if reads[callee] && !reads[name] {
+					reads[name] = true
+					changed = true
+				}
+			}
+		}
+	}
+	return reads
+}
@@ -0,0 +1,116 @@
+package application
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+func TestLinkSite(t *testing.T) {
+	builder := newTestBuilder(&domain.Site{PagesDir: "pages"}, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	home := testSource("index.html", nil)
+	about := testSource("about/index.html", map[string]interface{}{"weight": 1})
+	older := testSource("blog/older.html", map[string]interface{}{"date": "2024-01-01"})
+	newer := testSource("blog/newer.html", map[string]interface{}{"date": "2024-06-01"})
+
+	graph := builder.linkSite([]*pageSource{home, about, older, newer})
+
+	if got := pagePaths(graph.Pages); len(got) != 4 || got[0] != "about/index.html" || got[1] != "blog/newer.html" {
+		t.Errorf("Graph pages = %v", got)
+	}
+	if got := pagePaths(graph.Sections["blog"]); len(got) != 2 || got[0] != "blog/newer.html" {
+		t.Errorf("Blog section = %v", got)
+	}
+	if got := pagePaths(graph.Sections[""]); len(got) != 1 || got[0] != "index.html" {
+		t.Errorf("Root section = %v", got)
+	}
+	if graph.GetPage("/about/") != about.page || graph.GetPage("/missing/") != nil {
+		t.Error("GetPage did not find pages by URL")
+	}
+	if got := pagePaths(graph.Recent(1)); len(got) != 1 || got[0] != "blog/newer.html" {
+		t.Errorf("Recent(1) = %v", got)
+	}
+	if got := graph.Recent(10); len(got) != 2 {
+		t.Errorf("Expected only dated pages in Recent, got %v", pagePaths(got))
+	}
+	for _, src := range []*pageSource{home, about, older, newer} {
+		if src.page.Site != graph {
+			t.Errorf("Page %s should have the site graph", src.page.Path)
+		}
+	}
+}
+
+func TestLinkSite_DepsHash(t *testing.T) {
+	builder := newTestBuilder(&domain.Site{PagesDir: "pages"}, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	hashes := func(title string, sourceHash string) (string, string, string) {
+		post := testSource("blog/post.html", map[string]interface{}{"title": title})
+		post.entry.SourceHash = sourceHash
+		about := testSource("about/index.html", nil)
+		recent := testSource("recent.html", nil)
+		recent.body = "{{range .Site.Recent 5}}{{.URL}}{{end}}"
+		builder.linkSite([]*pageSource{post, about, recent})
+		return post.entry.DepsHash, about.entry.DepsHash, recent.entry.DepsHash
+	}
+
+	post1, about1, recent1 := hashes("Post", "a")
+	post2, about2, recent2 := hashes("Post", "b")
+	post3, about3, recent3 := hashes("Renamed", "c")
+	if post1 != post2 || about1 != about2 || recent1 != recent2 {
+		t.Error("Expected body edits to leave other pages' hashes unchanged")
+	}
+	if recent1 == recent3 {
+		t.Error("Expected a page reading .Site to depend on the front matter of all pages")
+	}
+	if about1 != about3 || post1 != post3 {
+		t.Error("Expected pages not reading .Site to ignore the front matter of other pages")
+	}
+
+	// A base template reading .Site makes every page depend on it
+	builder.siteTemplates = map[string]bool{domain.BaseTemplate: true}
+	_, about4, _ := hashes("Post", "a")
+	_, about5, _ := hashes("Renamed", "c")
+	if about4 == about5 {
+		t.Error("Expected every page to depend on the front matter of all pages")
+	}
+}
+
+func TestSiteTemplates(t *testing.T) {
+	tmpl := template.New("")
+	for name, source := range map[string]string{
+		"base.html":              `<nav>{{template "partials/nav.html" .}}</nav>{{.Content}}`,
+		"partials/nav.html":      `{{range .Site.Pages}}<a href="{{.URL}}">{{.Title}}</a>{{end}}`,
+		"layouts/plain.html":     `<h1>{{.Title}}</h1>{{.Content}}`,
+		"layouts/tagged.html":    `{{range .Terms.tags}}{{.Name}}{{end}}`,
+		"shortcodes/recent.html": `{{range .Page.Site.Recent 3}}{{.URL}}{{end}}`,
+	} {
+		template.Must(tmpl.New(name).Parse(source))
+	}
+
+	reads := siteTemplates(tmpl)
+	for name, expected := range map[string]bool{
+		"base.html":              true,
+		"partials/nav.html":      true,
+		"layouts/plain.html":     false,
+		"layouts/tagged.html":    true,
+		"shortcodes/recent.html": true,
+	} {
+		if reads[name] != expected {
+			t.Errorf("siteTemplates()[%q] = %v, expected %v", name, reads[name], expected)
+		}
+	}
+
+	builder := newTestBuilder(&domain.Site{}, NewMockFileSystem(), NewMockTemplateRenderer())
+	builder.siteTemplates = reads
+	if builder.readsSiteGraph("<p>Hi</p>", "plain") {
+		t.Error("Expected a plain page not to read the site graph")
+	}
+	if !builder.readsSiteGraph(`<p>Hi</p>{{< recent >}}`, "plain") {
+		t.Error("Expected a page calling a shortcode that reads .Site to read the site graph")
+	}
+	if !builder.readsSiteGraph("<p>Hi</p>", "") {
+		t.Error("Expected a page rendered with a base template that reads .Site to read the site graph")
+	}
+}
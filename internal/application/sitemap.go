@@ -0,0 +1,135 @@
+package application
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sitemapFile  = "sitemap.xml"
+	sitemapXmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+)
+
+// sitemapLimit is the maximum number of URLs in one sitemap file. Larger
+// sites get a sitemap index pointing to numbered sitemap files.
+var sitemapLimit = 50000
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	Lastmod    string `xml:"lastmod,omitempty"`
+	Changefreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	Lastmod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+// baseURL returns the base_url config value without a trailing slash
+func (sb *SiteBuilder) baseURL() string {
+	return strings.TrimSuffix(configString(sb.site.Config["base_url"]), "/")
+}
+
+// writeSitemap writes sitemap.xml listing every rendered page at its absolute
+// URL, leaving out pages whose robots meta contains noindex. The sitemap is
+// only written when base_url is set in config, since sitemap URLs must be
+// absolute.
+func (sb *SiteBuilder) writeSitemap(sources []*pageSource) error {
+	baseURL := sb.baseURL()
+	if baseURL == "" {
+		return nil
+	}
+
+	var urls []sitemapURL
+	lastmods := make(map[string]time.Time)
+	for _, src := range sources {
+		page := src.page
+		if strings.Contains(strings.ToLower(page.Meta.Robots), "noindex") {
+			continue
+		}
+		loc := baseURL + page.URL
+		lastmods[loc] = page.Lastmod
+		urls = append(urls, sitemapURL{
+			Loc:        loc,
+			Lastmod:    sitemapDate(page.Lastmod),
+			Changefreq: stringParam(page.Params, "changefreq"),
+			Priority:   priorityParam(page.Params),
+		})
+	}
+	sort.Slice(urls, func(i, j int) bool {
+		return urls[i].Loc < urls[j].Loc
+	})
+
+	if len(urls) <= sitemapLimit {
+		return sb.writeXML(sitemapFile, sitemapURLSet{Xmlns: sitemapXmlns, URLs: urls})
+	}
+
+	index := sitemapIndex{Xmlns: sitemapXmlns}
+	for n := 1; len(urls) > 0; n++ {
+		chunk := urls
+		if len(chunk) > sitemapLimit {
+			chunk = chunk[:sitemapLimit]
+		}
+		urls = urls[len(chunk):]
+
+		name := "sitemap-" + strconv.Itoa(n) + ".xml"
+		if err := sb.writeXML(name, sitemapURLSet{Xmlns: sitemapXmlns, URLs: chunk}); err != nil {
+			return err
+		}
+		var latest time.Time
+		for _, u := range chunk {
+			if lastmods[u.Loc].After(latest) {
+				latest = lastmods[u.Loc]
+			}
+		}
+		index.Sitemaps = append(index.Sitemaps, sitemapRef{Loc: baseURL + "/" + name, Lastmod: sitemapDate(latest)})
+	}
+	return sb.writeXML(sitemapFile, index)
+}
+
+// writeXML writes v as an XML document to name in the output directory
+func (sb *SiteBuilder) writeXML(name string, v interface{}) error {
+	content, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append([]byte(xml.Header), content...)
+	return sb.writeFile(filepath.Join(sb.outDir, name), append(content, '\n'))
+}
+
+// sitemapDate formats a time in the W3C datetime format sitemaps use, or
+// returns an empty string for the zero time
+func sitemapDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// priorityParam returns the priority front matter value as a string, or an
+// empty string if it is missing
+func priorityParam(params map[string]interface{}) string {
+	v, ok := params["priority"]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
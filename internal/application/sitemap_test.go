@@ -0,0 +1,95 @@
+package application
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+)
+
+func TestWriteSitemap(t *testing.T) {
+	site := &domain.Site{
+		PagesDir: "pages",
+		DistDir:  "dist",
+		Config:   map[string]interface{}{"base_url": template.HTML("https://example.com/")},
+	}
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	home := testSource("index.html", nil)
+	home.page.Lastmod = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	post := testSource("blog/post.html", map[string]interface{}{"changefreq": "weekly", "priority": 0.8})
+	hidden := testSource("private/index.html", nil)
+	hidden.page.Meta.Robots = "NoIndex,follow"
+
+	if err := builder.writeSitemap([]*pageSource{post, home, hidden}); err != nil {
+		t.Fatalf("writeSitemap failed: %v", err)
+	}
+
+	content := fs.written["dist/sitemap.xml"].String()
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/</loc>
+    <lastmod>2024-06-01T00:00:00Z</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/blog/post.html</loc>
+    <changefreq>weekly</changefreq>
+    <priority>0.8</priority>
+  </url>
+</urlset>
+`
+	if content != expected {
+		t.Errorf("Unexpected sitemap:\n%s", content)
+	}
+}
+
+func TestWriteSitemap_Index(t *testing.T) {
+	defer func(limit int) { sitemapLimit = limit }(sitemapLimit)
+	sitemapLimit = 2
+
+	site := &domain.Site{
+		PagesDir: "pages",
+		DistDir:  "dist",
+		Config:   map[string]interface{}{"base_url": "https://example.com"},
+	}
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	var sources []*pageSource
+	for _, rel := range []string{"a.html", "b.html", "c.html"} {
+		sources = append(sources, testSource(rel, nil))
+	}
+	if err := builder.writeSitemap(sources); err != nil {
+		t.Fatalf("writeSitemap failed: %v", err)
+	}
+
+	index := fs.written["dist/sitemap.xml"].String()
+	if !strings.Contains(index, "<sitemapindex") ||
+		!strings.Contains(index, "<loc>https://example.com/sitemap-1.xml</loc>") ||
+		!strings.Contains(index, "<loc>https://example.com/sitemap-2.xml</loc>") {
+		t.Errorf("Unexpected sitemap index:\n%s", index)
+	}
+	if got := strings.Count(fs.written["dist/sitemap-1.xml"].String(), "<url>"); got != 2 {
+		t.Errorf("Expected 2 URLs in the first sitemap, got %d", got)
+	}
+	if got := fs.written["dist/sitemap-2.xml"].String(); !strings.Contains(got, "https://example.com/c.html") {
+		t.Errorf("Unexpected second sitemap:\n%s", got)
+	}
+}
+
+func TestWriteSitemap_NoBaseURL(t *testing.T) {
+	site := &domain.Site{PagesDir: "pages", DistDir: "dist", Config: map[string]interface{}{}}
+	fs := NewMockFileSystem()
+	builder := newTestBuilder(site, fs, NewMockTemplateRenderer())
+
+	if err := builder.writeSitemap([]*pageSource{testSource("index.html", nil)}); err != nil {
+		t.Fatalf("writeSitemap failed: %v", err)
+	}
+	if _, ok := fs.written["dist/sitemap.xml"]; ok {
+		t.Error("Expected no sitemap without a base URL")
+	}
+}
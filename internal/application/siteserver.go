@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 
 	"html/template"
@@ -58,6 +59,7 @@ type SiteServer struct {
 	reloadCh  chan struct{}
 	clients   map[http.ResponseWriter]bool
 	clientsMu sync.Mutex
+	buildMu   sync.RWMutex // held by rebuilds, which swap the dist directory
 }
 
 // NewSiteServer creates a new SiteServer
@@ -105,12 +107,68 @@ func (ss *SiteServer) Serve() error {
 	if ss.site.EnableAutoReload {
 		mux.HandleFunc("/__reload", ss.handleReload)
 	}
-	mux.Handle("/", http.FileServer(http.Dir(ss.site.DistDir)))
+	mux.Handle("/", ss.waitForBuild(ss.redirect(ss.headers(http.FileServer(http.Dir(ss.site.DistDir))))))
 
 	log.Printf("Serving %s on http://localhost:%s", ss.site.DistDir, ss.port)
 	return ss.server.ListenAndServe(":"+ss.port, mux)
 }
 
+// waitForBuild holds requests while the site is rebuilt. A build swaps the new
+// output into the dist directory with two renames, and in between the
+// directory does not exist.
+func (ss *SiteServer) waitForBuild(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ss.buildMu.RLock()
+		defer ss.buildMu.RUnlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// redirect applies the redirects file of the built site before serving files,
+// like Cloudflare Pages does. The file is read on every request so redirects
+// follow rebuilds.
+func (ss *SiteServer) redirect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, err := os.ReadFile(filepath.Join(ss.site.DistDir, domain.RedirectsFile))
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		redirects, err := parseRedirects(content)
+		if err != nil {
+			log.Printf("%s: %v", domain.RedirectsFile, err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		to, status, ok := matchRedirect(redirects, r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.URL.RawQuery != "" && !strings.Contains(to, "?") {
+			to += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, to, status)
+	})
+}
+
+// headers sets the headers of the headers file of the built site on the files
+// it serves, like Cloudflare Pages does
+func (ss *SiteServer) headers(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if content, err := os.ReadFile(filepath.Join(ss.site.DistDir, domain.HeadersFile)); err == nil {
+			rules, err := parseHeaders(content)
+			if err != nil {
+				log.Printf("%s: %v", domain.HeadersFile, err)
+			}
+			for name, values := range matchHeaders(rules, r.URL.Path) {
+				w.Header()[name] = values
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (ss *SiteServer) handleReload(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Client connected to /__reload")
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -185,6 +243,12 @@ func (ss *SiteServer) initWatcher() (*fsnotify.Watcher, error) {
 	}
 
 	dirs := []string{ss.site.PagesDir, ss.site.TemplatesDir, ss.site.AssetsDir}
+	// The data and i18n directories are optional
+	for _, dir := range []string{ss.site.DataDir, ss.site.I18nDir} {
+		if _, err := os.Stat(dir); dir != "" && err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
 	for _, dir := range dirs {
 		if err := addDir(dir); err != nil {
 			log.Printf("Error watching %s: %v", dir, err)
@@ -221,7 +285,10 @@ func (ss *SiteServer) handleFileEvent(event fsnotify.Event, watcher *fsnotify.Wa
 			}
 		}
 		log.Printf("File changed: %s", event.Name)
-		if err := ss.builder.Build(); err != nil {
+		ss.buildMu.Lock()
+		err := ss.builder.Build()
+		ss.buildMu.Unlock()
+		if err != nil {
 			log.Printf("Build error: %v", err)
 		} else {
 			ss.notifyClients()
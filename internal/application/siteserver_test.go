@@ -3,8 +3,12 @@ package application
 import (
 	"bytes"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/EmiraLabs/stw-cli/internal/domain"
 )
@@ -150,3 +154,107 @@ func TestSiteServer_clientsConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestSiteServer_initWatcher(t *testing.T) {
+	root := t.TempDir()
+	site := &domain.Site{
+		PagesDir:     filepath.Join(root, "pages"),
+		TemplatesDir: filepath.Join(root, "templates"),
+		AssetsDir:    filepath.Join(root, "assets"),
+		DataDir:      filepath.Join(root, "data"),
+		I18nDir:      filepath.Join(root, "i18n"),
+		ConfigPath:   filepath.Join(root, "config.yaml"),
+	}
+	for _, dir := range []string{site.PagesDir, site.TemplatesDir, site.AssetsDir, filepath.Join(site.DataDir, "team"), site.I18nDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := NewSiteServer(site, &mockSiteBuilder{}, "8080")
+
+	watcher, err := server.initWatcher()
+	if err != nil {
+		t.Fatalf("initWatcher failed: %v", err)
+	}
+	defer watcher.Close()
+
+	watched := make(map[string]bool)
+	for _, name := range watcher.WatchList() {
+		watched[name] = true
+	}
+	for _, dir := range []string{site.PagesDir, site.DataDir, filepath.Join(site.DataDir, "team"), site.I18nDir} {
+		if !watched[dir] {
+			t.Errorf("Expected %s to be watched, got %v", dir, watcher.WatchList())
+		}
+	}
+}
+
+func TestSiteServer_redirect(t *testing.T) {
+	dist := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dist, domain.RedirectsFile), []byte("/docs/v1/* /docs/:splat 301\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := &SiteServer{site: &domain.Site{DistDir: dist}}
+	handler := server.redirect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("file"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/docs/v1/install/?ref=old", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/docs/install/?ref=old" {
+		t.Errorf("Expected a redirect to /docs/install/?ref=old, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/docs/install/", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "file" {
+		t.Errorf("Expected other paths to be served, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSiteServer_headers(t *testing.T) {
+	dist := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dist, domain.HeadersFile), []byte("/assets/*\n  Cache-Control: max-age=60\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := &SiteServer{site: &domain.Site{DistDir: dist}}
+	handler := server.headers(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("file"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/assets/app.css", nil))
+	if got := rec.Header().Get("Cache-Control"); got != "max-age=60" || rec.Body.String() != "file" {
+		t.Errorf("Expected the file with Cache-Control max-age=60, got %q %q", got, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/index.html", nil))
+	if got := rec.Header().Get("Cache-Control"); got != "" {
+		t.Errorf("Expected no Cache-Control outside /assets/, got %q", got)
+	}
+}
+
+func TestSiteServer_waitForBuild(t *testing.T) {
+	server := &SiteServer{site: &domain.Site{}}
+	handler := server.waitForBuild(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("file"))
+	}))
+
+	server.buildMu.Lock()
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		done <- rec
+	}()
+	select {
+	case <-done:
+		t.Fatal("Expected the request to wait for the build")
+	case <-time.After(20 * time.Millisecond):
+	}
+	server.buildMu.Unlock()
+	if rec := <-done; rec.Body.String() != "file" {
+		t.Errorf("Expected the file after the build, got %q", rec.Body.String())
+	}
+}
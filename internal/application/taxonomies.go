@@ -0,0 +1,222 @@
+package application
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+// taxonomyConfig is a taxonomy declared under the taxonomies config key, such
+// as tags: tag
+type taxonomyConfig struct {
+	name     string
+	singular string
+}
+
+// configTaxonomies returns the taxonomies declared in config sorted by name
+func configTaxonomies(config map[string]interface{}) []taxonomyConfig {
+	declared, _ := config["taxonomies"].(map[string]interface{})
+	var taxonomies []taxonomyConfig
+	for name, singular := range declared {
+		taxonomies = append(taxonomies, taxonomyConfig{name: name, singular: configString(singular)})
+	}
+	sort.Slice(taxonomies, func(i, j int) bool {
+		return taxonomies[i].name < taxonomies[j].name
+	})
+	return taxonomies
+}
+
+// linkTaxonomies collects the terms pages list in front matter for every
+// taxonomy declared in config and returns the generated overview and term
+// pages. On multilingual sites sources are the pages of lang, and the
+// generated pages are written under lang. A page in the pages directory at the
+// path of a generated page takes its place and receives its taxonomy and term.
+// Generated pages and pages at their paths are rebuilt when a page they list
+// changes; other pages that read terms depend on the pages hash, see linkSite.
+// Terms whose names differ only in case share a page;
+// other names with the same slug are an error.
+func (sb *SiteBuilder) linkTaxonomies(sources []*pageSource, lang *domain.Language, siteMeta meta.Meta, graph *domain.SiteGraph) ([]*pageSource, error) {
+	configs := configTaxonomies(sb.site.Config)
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	home := "/"
+	if lang != nil {
+		home = lang.URL
+	}
+
+	index := make(map[string]*domain.Taxonomy)
+	for _, c := range configs {
+		taxonomy := &domain.Taxonomy{Name: c.name, Singular: c.singular, URL: home + c.name + "/"}
+		index[c.name] = taxonomy
+
+		terms := make(map[string]*domain.Term)
+		for _, src := range sources {
+			for _, name := range stringList(src.page.Params[c.name]) {
+				slug := urlize(name)
+				if slug == "" {
+					continue
+				}
+				term, ok := terms[slug]
+				if !ok {
+					term = &domain.Term{Name: name, Slug: slug, URL: taxonomy.URL + slug + "/"}
+					terms[slug] = term
+					taxonomy.Terms = append(taxonomy.Terms, term)
+				} else if !strings.EqualFold(term.Name, name) {
+					return nil, fmt.Errorf("%s: %s %q and %q have the same slug %q", src.path, c.name, term.Name, name, slug)
+				}
+				// Skip terms listed twice by the same page
+				if n := len(term.Pages); n > 0 && term.Pages[n-1] == src.page {
+					continue
+				}
+				term.Pages = append(term.Pages, src.page)
+				if src.page.Terms == nil {
+					src.page.Terms = make(map[string][]*domain.Term)
+				}
+				src.page.Terms[c.name] = append(src.page.Terms[c.name], term)
+			}
+		}
+
+		sort.Slice(taxonomy.Terms, func(i, j int) bool {
+			return taxonomy.Terms[i].Slug < taxonomy.Terms[j].Slug
+		})
+		for _, term := range taxonomy.Terms {
+			sortPages(term.Pages, "")
+		}
+	}
+
+	claimed := make(map[string]*pageSource)
+	pageSources := make(map[*domain.Page]*pageSource, len(sources))
+	for _, src := range sources {
+		claimed[src.page.Path] = src
+		pageSources[src.page] = src
+		src.page.Taxonomies = index
+	}
+
+	var generated []*pageSource
+	add := func(langRel, title, layout string, taxonomy *domain.Taxonomy, term *domain.Term) {
+		rel := languagePath(lang, langRel)
+		var pages, listed []*domain.Page
+		if term != nil {
+			pages = term.Pages
+			listed = term.Pages
+		} else {
+			for _, t := range taxonomy.Terms {
+				listed = append(listed, t.Pages...)
+			}
+		}
+		var listedSources []*pageSource
+		for _, page := range listed {
+			listedSources = append(listedSources, pageSources[page])
+		}
+		deps := sourcesHash(listedSources)
+		if src, ok := claimed[rel]; ok {
+			src.page.Taxonomy = taxonomy
+			src.page.Term = term
+			if term != nil {
+				src.page.Pages = pages
+			}
+			src.entry.DepsHash = hashBytes([]byte(src.entry.DepsHash + "\n" + deps))
+			return
+		}
+		if sb.readsSiteGraph("", layout) {
+			deps = hashBytes([]byte(deps + "\n" + sb.pagesHash))
+		}
+		generated = append(generated, &pageSource{
+			path:     rel,
+			treePath: rel,
+			entry: cacheEntry{
+				Source:        sb.site.ConfigPath,
+				TemplatesHash: sb.templatesHash,
+				ConfigHash:    sb.configHash,
+				DepsHash:      deps,
+			},
+			page: &domain.Page{
+				Title:      title,
+				Path:       rel,
+				URL:        pageURL(rel),
+				IsDev:      sb.site.EnableAutoReload,
+				Config:     sb.site.Config,
+				Data:       sb.data,
+				Meta:       siteMeta,
+				Params:     map[string]interface{}{},
+				Layout:     layout,
+				Section:    pageSection(langRel),
+				Lastmod:    latestLastmod(listed),
+				Pages:      pages,
+				Site:       graph,
+				Taxonomies: index,
+				Taxonomy:   taxonomy,
+				Term:       term,
+				Language:   lang,
+			},
+		})
+	}
+	for _, c := range configs {
+		taxonomy := index[c.name]
+		add(filepath.Join(c.name, domain.IndexFile), strings.Title(c.name), domain.TaxonomyLayout, taxonomy, nil)
+		for _, term := range taxonomy.Terms {
+			add(filepath.Join(c.name, term.Slug, domain.IndexFile), term.Name, domain.TermLayout, taxonomy, term)
+		}
+	}
+	return generated, nil
+}
+
+// latestLastmod returns the newest modification time of pages
+func latestLastmod(pages []*domain.Page) time.Time {
+	var latest time.Time
+	for _, page := range pages {
+		if page.Lastmod.After(latest) {
+			latest = page.Lastmod
+		}
+	}
+	return latest
+}
+
+// urlizeWords are the characters urlize spells out, so that terms such as C,
+// C++ and C# get different slugs
+var urlizeWords = map[rune]string{'+': "plus", '#': "sharp"}
+
+// urlize turns a term into a URL path segment: lower case, with + and #
+// spelled out as words and every run of other characters than letters and
+// digits replaced by a hyphen, such as c-plus-plus for C++
+func urlize(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		word, isWord := urlizeWords[r]
+		if !isWord && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			hyphen = true
+			continue
+		}
+		if (hyphen || isWord) && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		if isWord {
+			b.WriteString(word)
+		} else {
+			b.WriteRune(r)
+		}
+		hyphen = isWord
+	}
+	return b.String()
+}
+
+// configString returns a config value as a string. Strings in config are
+// loaded as template.HTML so templates can output them unescaped.
+func configString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case template.HTML:
+		return string(v)
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package application
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/EmiraLabs/stw-cli/internal/domain"
+	"github.com/EmiraLabs/stw-cli/internal/meta"
+)
+
+func TestLinkTaxonomies(t *testing.T) {
+	site := &domain.Site{
+		PagesDir:   "pages",
+		ConfigPath: "config.yaml",
+		Config: map[string]interface{}{
+			"taxonomies": map[string]interface{}{
+				"tags":       template.HTML("tag"),
+				"categories": template.HTML("category"),
+			},
+		},
+	}
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	first := testSource("blog/first.html", map[string]interface{}{
+		"date": "2024-01-01",
+		"tags": []interface{}{"Go", "Web Dev", "go"},
+	})
+	second := testSource("blog/second.html", map[string]interface{}{
+		"date":       "2024-06-01",
+		"tags":       []interface{}{"go"},
+		"categories": "Tutorials",
+	})
+	about := testSource("about/index.html", nil)
+	sources := []*pageSource{first, second, about}
+
+	generated, err := builder.linkTaxonomies(sources, nil, meta.Meta{}, nil)
+	if err != nil {
+		t.Fatalf("linkTaxonomies failed: %v", err)
+	}
+
+	var paths []string
+	for _, src := range generated {
+		paths = append(paths, src.page.Path)
+	}
+	expected := []string{
+		"categories/index.html",
+		"categories/tutorials/index.html",
+		"tags/index.html",
+		"tags/go/index.html",
+		"tags/web-dev/index.html",
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("Generated pages = %v, expected %v", paths, expected)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("Generated page %d = %s, expected %s", i, paths[i], expected[i])
+		}
+	}
+
+	tags := generated[2].page
+	if tags.Layout != domain.TaxonomyLayout || tags.Taxonomy == nil || tags.Taxonomy.Singular != "tag" || tags.URL != "/tags/" {
+		t.Errorf("Unexpected overview page: %+v", tags)
+	}
+	if len(tags.Taxonomy.Terms) != 2 || tags.Taxonomy.Terms[0].Name != "Go" || tags.Taxonomy.Terms[1].URL != "/tags/web-dev/" {
+		t.Errorf("Unexpected terms: %+v", tags.Taxonomy.Terms)
+	}
+
+	goPage := generated[3].page
+	if goPage.Layout != domain.TermLayout || goPage.Term == nil || goPage.Title != "Go" {
+		t.Errorf("Unexpected term page: %+v", goPage)
+	}
+	if got := pagePaths(goPage.Pages); len(got) != 2 || got[0] != "blog/second.html" || got[1] != "blog/first.html" {
+		t.Errorf("Term pages = %v, expected newest first", got)
+	}
+
+	if got := first.page.Terms["tags"]; len(got) != 2 || got[0].Slug != "go" || got[1].Slug != "web-dev" {
+		t.Errorf("First page terms = %+v", got)
+	}
+	if about.page.Terms != nil {
+		t.Errorf("Untagged page should have no terms, got %+v", about.page.Terms)
+	}
+	for _, page := range []*domain.Page{first.page, about.page, goPage} {
+		if page.Taxonomies["categories"] == nil || page.Taxonomies["tags"] == nil {
+			t.Errorf("Page %s should see the term index", page.Path)
+		}
+	}
+}
+
+func TestLinkTaxonomies_ClaimedPage(t *testing.T) {
+	site := &domain.Site{
+		PagesDir: "pages",
+		Config: map[string]interface{}{
+			"taxonomies": map[string]interface{}{"tags": template.HTML("tag")},
+		},
+	}
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	post := testSource("blog/post.html", map[string]interface{}{"tags": "go"})
+	custom := testSource("tags/go/index.html", nil)
+
+	generated, err := builder.linkTaxonomies([]*pageSource{post, custom}, nil, meta.Meta{}, nil)
+	if err != nil {
+		t.Fatalf("linkTaxonomies failed: %v", err)
+	}
+
+	if len(generated) != 1 || generated[0].page.Path != "tags/index.html" {
+		t.Fatalf("Expected only the overview page to be generated, got %d pages", len(generated))
+	}
+	if custom.page.Term == nil || custom.page.Term.Slug != "go" {
+		t.Error("Page at a term path should receive the term")
+	}
+	if got := pagePaths(custom.page.Pages); len(got) != 1 || got[0] != "blog/post.html" {
+		t.Errorf("Term pages = %v", got)
+	}
+}
+
+func TestLinkTaxonomies_DepsHash(t *testing.T) {
+	site := &domain.Site{
+		PagesDir: "pages",
+		Config: map[string]interface{}{
+			"taxonomies": map[string]interface{}{"tags": template.HTML("tag")},
+		},
+	}
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	termHash := func(postHash, aboutTitle string) string {
+		post := testSource("blog/post.html", map[string]interface{}{"tags": "go"})
+		post.entry.SourceHash = postHash
+		about := testSource("about/index.html", map[string]interface{}{"title": aboutTitle})
+		about.entry.SourceHash = aboutTitle
+		builder.linkSite([]*pageSource{post, about})
+		generated, err := builder.linkTaxonomies([]*pageSource{post, about}, nil, meta.Meta{}, nil)
+		if err != nil {
+			t.Fatalf("linkTaxonomies failed: %v", err)
+		}
+		return generated[1].entry.DepsHash
+	}
+
+	first := termHash("a", "About")
+	if termHash("b", "About") == first {
+		t.Error("Expected the term page to be rebuilt when a tagged page changes")
+	}
+	if termHash("a", "Team") != first {
+		t.Error("Expected the term page to ignore pages without the term")
+	}
+}
+
+func TestLinkTaxonomies_SlugCollision(t *testing.T) {
+	site := &domain.Site{
+		PagesDir: "pages",
+		Config: map[string]interface{}{
+			"taxonomies": map[string]interface{}{"tags": template.HTML("tag")},
+		},
+	}
+	builder := newTestBuilder(site, NewMockFileSystem(), NewMockTemplateRenderer())
+
+	similar := []*pageSource{
+		testSource("blog/c.html", map[string]interface{}{"tags": []interface{}{"C", "C++", "C#"}}),
+	}
+	generated, err := builder.linkTaxonomies(similar, nil, meta.Meta{}, nil)
+	if err != nil {
+		t.Fatalf("linkTaxonomies failed: %v", err)
+	}
+	if len(generated) != 4 {
+		t.Errorf("Expected C, C++ and C# to get a page each, got %d pages", len(generated))
+	}
+
+	colliding := []*pageSource{
+		testSource("blog/first.html", map[string]interface{}{"tags": "Web Dev"}),
+		testSource("blog/second.html", map[string]interface{}{"tags": "web-dev"}),
+	}
+	_, err = builder.linkTaxonomies(colliding, nil, meta.Meta{}, nil)
+	expected := `pages/blog/second.html: tags "Web Dev" and "web-dev" have the same slug "web-dev"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("linkTaxonomies error = %v, expected %q", err, expected)
+	}
+}
+
+func TestUrlize(t *testing.T) {
+	tests := map[string]string{
+		"Go":           "go",
+		"Web Dev":      "web-dev",
+		" C++ & Rust ": "c-plus-plus-rust",
+		"C#":           "c-sharp",
+		"a+b":          "a-plus-b",
+		"Ünïcode":      "ünïcode",
+		"--":           "",
+	}
+	for input, expected := range tests {
+		if got := urlize(input); got != expected {
+			t.Errorf("urlize(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "net/http"
+
+// HeadersFile is the file in the dist directory listing the site's custom
+// response headers in Cloudflare Pages format
+const HeadersFile = "_headers"
+
+// HeaderRule sets response headers on the URL paths matching Path, which can
+// hold a * splat and :name placeholders like a Redirect
+type HeaderRule struct {
+	Path    string
+	Headers http.Header
+}
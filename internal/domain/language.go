@@ -0,0 +1,33 @@
+package domain
+
+import "fmt"
+
+// Language is a language declared under the languages config key. Pages of a
+// language are written under /<code>/.
+type Language struct {
+	Code    string            // language code, such as de
+	Name    string            // display name, such as Deutsch
+	Weight  int               // order of the language in language lists
+	URL     string            // URL path of the language's home page, such as /de/
+	Default bool              // the language of pages without a language
+	Strings map[string]string // translations by key from the language's catalog
+}
+
+// Translate returns the translation of key, formatted with args when given.
+// Keys missing from the catalog are returned unchanged.
+func (l *Language) Translate(key string, args ...interface{}) string {
+	if l == nil {
+		return format(key, args)
+	}
+	if s, ok := l.Strings[key]; ok {
+		return format(s, args)
+	}
+	return format(key, args)
+}
+
+func format(s string, args []interface{}) string {
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"html/template"
+	"time"
 
 	"github.com/EmiraLabs/stw-cli/internal/meta"
 )
@@ -13,5 +14,35 @@ type Page struct {
 	Path    string // relative path
 	IsDev   bool
 	Config  map[string]interface{}
+	Data    map[string]interface{} // data files by path, such as .Data.team.members
+	Record  map[string]interface{} // data record of pages generated from a data source
 	Meta    meta.Meta
+	Params  map[string]interface{} // all front matter keys
+	Layout  string                 // layout named in front matter, empty for base.html
+	URL     string                 // URL path, such as /blog/ or /blog/my-post.html
+	Section string                 // top-level directory under pages/, empty for root pages
+	Date    time.Time              // date front matter key
+	Lastmod time.Time              // lastmod front matter key, or the modification time of the source
+	Weight  int                    // weight front matter key
+	Pages   []*Page                // pages listed by list and term pages
+	Pager   *Pager                 // current page of a paginated listing
+	Site    *SiteGraph             // every page of the site
+
+	Draft       bool      // draft front matter key, only rendered by stw serve
+	PublishDate time.Time // publish_date front matter key
+	ExpiryDate  time.Time // expiry_date front matter key
+
+	Language     *Language // nil on sites without languages
+	Translations []*Page   // the page in the site's other languages
+
+	Terms      map[string][]*Term   // terms of the page by taxonomy
+	Taxonomies map[string]*Taxonomy // every taxonomy of the site by name
+	Taxonomy   *Taxonomy            // set on taxonomy overview and term pages
+	Term       *Term                // set on term pages
+}
+
+// T returns the translation of key in the page's language, formatted with args
+// when given, so templates can call {{.T "read_more"}}
+func (p Page) T(key string, args ...interface{}) string {
+	return p.Language.Translate(key, args...)
 }
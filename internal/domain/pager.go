@@ -0,0 +1,18 @@
+package domain
+
+// PagerDir is the directory holding the second and later pages of a
+// paginated listing, such as /blog/page/2/
+const PagerDir = "page"
+
+// Pager is one page of a paginated listing
+type Pager struct {
+	PageNumber int     // 1-based number of this page
+	TotalPages int     // number of pages in the listing
+	TotalItems int     // number of items across all pages
+	PageSize   int     // maximum number of items per page
+	Pages      []*Page // items on this page
+	First      string  // URL path of the first page
+	Last       string  // URL path of the last page
+	Prev       string  // URL path of the previous page, empty on the first page
+	Next       string  // URL path of the next page, empty on the last page
+}
@@ -0,0 +1,13 @@
+package domain
+
+// RedirectsFile is the file in the dist directory listing the site's
+// redirects in Cloudflare Pages format
+const RedirectsFile = "_redirects"
+
+// Redirect sends requests for one URL path to another URL. From can end in a
+// * splat or hold :name placeholders, which To can reuse as :splat and :name.
+type Redirect struct {
+	From   string
+	To     string
+	Status int
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "html/template"
+
+// ShortcodesDir is the directory under the templates directory holding
+// shortcode templates
+const ShortcodesDir = "shortcodes"
+
+// Shortcode is the data a shortcode template is executed with
+type Shortcode struct {
+	Name   string
+	Params map[string]string // named arguments
+	Inner  template.HTML     // content between the opening and closing tags
+	Page   *Page             // page the shortcode is called from
+}
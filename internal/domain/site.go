@@ -1,11 +1,11 @@
 package domain
 
 const (
-	IndexFile          = "index.html"
-	BaseTemplate       = "base.html"
-	HeaderTemplateFile = "components/header.html"
-	FooterTemplateFile = "components/footer.html"
-	HeadTemplateFile   = "partials/head.html"
+	IndexFile    = "index.html"
+	PageExt      = ".html"
+	MarkdownExt  = ".md"
+	BaseTemplate = "base.html"
+	LayoutsDir   = "layouts"
 )
 
 // Site represents the static site configuration
@@ -13,8 +13,15 @@ type Site struct {
 	PagesDir         string
 	TemplatesDir     string
 	AssetsDir        string
+	DataDir          string // data files exposed to templates, optional
+	I18nDir          string // translation catalogs such as de.yaml, optional
 	DistDir          string
 	EnableAutoReload bool
 	Config           map[string]interface{}
 	ConfigPath       string
+	CacheDir         string // incremental builds are disabled when empty
+	Jobs             int    // pages rendered in parallel, GOMAXPROCS when < 1
+	BuildDrafts      bool   // render pages marked draft in front matter
+	Minify           bool   // minify CSS, JavaScript, JSON and SVG assets
+	MinifyHTML       bool   // minify rendered pages
 }
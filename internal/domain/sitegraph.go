@@ -0,0 +1,52 @@
+package domain
+
+import "sort"
+
+// SiteGraph holds every page of the site, or of one language on multilingual
+// sites, so templates can build menus, recent posts lists and cross-links. Its
+// pages carry their front matter but no rendered content.
+type SiteGraph struct {
+	Pages    []*Page            // every page, sorted like section pages
+	Sections map[string][]*Page // pages by top-level section, root pages under ""
+
+	Languages []*Language // languages of the site, empty on single-language sites
+
+	byURL map[string]*Page
+}
+
+// NewSiteGraph creates a SiteGraph from pages in the order they are listed
+func NewSiteGraph(pages []*Page) *SiteGraph {
+	g := &SiteGraph{
+		Pages:    pages,
+		Sections: make(map[string][]*Page),
+		byURL:    make(map[string]*Page, len(pages)),
+	}
+	for _, page := range pages {
+		g.Sections[page.Section] = append(g.Sections[page.Section], page)
+		g.byURL[page.URL] = page
+	}
+	return g
+}
+
+// GetPage returns the page with the given URL path, such as /about/, or nil
+// if there is none
+func (g *SiteGraph) GetPage(url string) *Page {
+	return g.byURL[url]
+}
+
+// Recent returns up to n pages with a date, newest first
+func (g *SiteGraph) Recent(n int) []*Page {
+	var pages []*Page
+	for _, page := range g.Pages {
+		if !page.Date.IsZero() {
+			pages = append(pages, page)
+		}
+	}
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].Date.After(pages[j].Date)
+	})
+	if len(pages) > n {
+		pages = pages[:n]
+	}
+	return pages
+}
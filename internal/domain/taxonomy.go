@@ -0,0 +1,22 @@
+package domain
+
+const (
+	TaxonomyLayout = "taxonomy"
+	TermLayout     = "term"
+)
+
+// Taxonomy is a grouping of pages declared in config, such as tags
+type Taxonomy struct {
+	Name     string  // plural name, such as tags
+	Singular string  // singular name, such as tag
+	URL      string  // URL path of the overview page, such as /tags/
+	Terms    []*Term // sorted by slug
+}
+
+// Term is a value of a taxonomy and the pages that list it
+type Term struct {
+	Name  string
+	Slug  string
+	URL   string  // URL path of the term page, such as /tags/go/
+	Pages []*Page // sorted like section pages
+}
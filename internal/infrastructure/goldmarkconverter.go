@@ -0,0 +1,36 @@
+package infrastructure
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// GoldmarkConverter implements MarkdownConverter using goldmark with GitHub
+// Flavored Markdown, footnotes and automatic heading IDs enabled
+type GoldmarkConverter struct {
+	md goldmark.Markdown
+}
+
+// NewGoldmarkConverter creates a new GoldmarkConverter
+func NewGoldmarkConverter() *GoldmarkConverter {
+	return &GoldmarkConverter{
+		md: goldmark.New(
+			goldmark.WithExtensions(extension.GFM, extension.Footnote),
+			goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+	}
+}
+
+// Convert converts Markdown source to HTML
+func (c *GoldmarkConverter) Convert(source []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.md.Convert(source, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,28 @@
+package infrastructure
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoldmarkConverter_Convert(t *testing.T) {
+	converter := NewGoldmarkConverter()
+	source := "# Hello World\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n```go\nfmt.Println(\"hi\")\n```\n\nNote[^1]\n\n[^1]: Footnote text\n"
+
+	out, err := converter.Convert([]byte(source))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	html := string(out)
+	expected := []string{
+		`<h1 id="hello-world">Hello World</h1>`,
+		"<table>",
+		`<code class="language-go">`,
+		`class="footnotes"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(html, e) {
+			t.Errorf("Expected output to contain %q, got %s", e, html)
+		}
+	}
+}
@@ -4,26 +4,81 @@ import (
 	"encoding/json"
 	"html/template"
 	"io"
+	"path/filepath"
+	"strings"
 )
 
 // GoTemplateRenderer implements TemplateRenderer using html/template
 type GoTemplateRenderer struct {
+	FS   FileSystem // reads the files of ParseTemplates, the OS file system when nil
 	tmpl *template.Template
 }
 
-// ParseFiles parses the named files into a template
-func (tr *GoTemplateRenderer) ParseFiles(filenames ...string) (*template.Template, error) {
-	funcMap := template.FuncMap{
+// funcMap returns the custom functions available to every template
+func funcMap() template.FuncMap {
+	return template.FuncMap{
 		"toJson": func(v interface{}) template.JS {
 			b, _ := json.Marshal(v)
 			return template.JS(b)
 		},
+		// The site builder replaces asset with the asset names of the build
+		"asset": func(name string) string {
+			return "/assets/" + strings.TrimPrefix(name, "/")
+		},
 	}
+}
+
+// ParseFiles parses the named files into a template
+func (tr *GoTemplateRenderer) ParseFiles(filenames ...string) (*template.Template, error) {
 	var err error
-	tr.tmpl, err = template.New("").Funcs(funcMap).ParseFiles(filenames...)
+	tr.tmpl, err = template.New("").Funcs(funcMap()).ParseFiles(filenames...)
 	return tr.tmpl, err
 }
 
+// ParseTemplates reads the named files through the renderer's file system and
+// parses them into a template, naming each after its slash-separated path
+// relative to root, such as "components/header.html".
+// As with ParseFiles, a file is also available under its base name unless that
+// name is already defined or shared by several files.
+func (tr *GoTemplateRenderer) ParseTemplates(root string, filenames ...string) (*template.Template, error) {
+	fsys := tr.FS
+	if fsys == nil {
+		fsys = &OSFileSystem{}
+	}
+	tmpl := template.New("").Funcs(funcMap())
+	names := make(map[string]string, len(filenames))
+	bases := make(map[string]int, len(filenames))
+	for _, filename := range filenames {
+		content, err := fsys.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(root, filename)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.ToSlash(rel)
+		if _, err := tmpl.New(name).Parse(string(content)); err != nil {
+			return nil, err
+		}
+		names[filename] = name
+		bases[filepath.Base(filename)]++
+	}
+
+	for _, filename := range filenames {
+		base := filepath.Base(filename)
+		if bases[base] > 1 || tmpl.Lookup(base) != nil {
+			continue
+		}
+		if _, err := tmpl.AddParseTree(base, tmpl.Lookup(names[filename]).Tree.Copy()); err != nil {
+			return nil, err
+		}
+	}
+
+	tr.tmpl = tmpl
+	return tmpl, nil
+}
+
 // ExecuteTemplate applies the template associated with t that has the given name to the specified data object
 func (tr *GoTemplateRenderer) ExecuteTemplate(wr io.Writer, name string, data interface{}) error {
 	return tr.tmpl.ExecuteTemplate(wr, name, data)
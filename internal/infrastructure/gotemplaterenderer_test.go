@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"html/template"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -40,6 +41,95 @@ func TestGoTemplateRenderer_ParseFiles_Error(t *testing.T) {
 	}
 }
 
+func TestGoTemplateRenderer_ParseTemplates(t *testing.T) {
+	renderer := &GoTemplateRenderer{}
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, "components"), 0755)
+	os.MkdirAll(filepath.Join(tempDir, "partials"), 0755)
+	os.MkdirAll(filepath.Join(tempDir, "layouts"), 0755)
+
+	files := map[string]string{
+		"base.html":               `{{template "components/sidebar.html" .}}|{{template "sidebar.html" .}}|{{template "head.html" .}}`,
+		"components/sidebar.html": `<aside>{{.Title}}</aside>`,
+		"partials/head.html":      `{{define "head.html"}}<title>{{.Title}}</title>{{end}}`,
+		"layouts/base.html":       `<div>{{.Title}}</div>`,
+	}
+	var filenames []string
+	for name, content := range files {
+		filename := filepath.Join(tempDir, name)
+		os.WriteFile(filename, []byte(content), 0644)
+		filenames = append(filenames, filename)
+	}
+
+	tmpl, err := renderer.ParseTemplates(tempDir, filenames...)
+	if err != nil {
+		t.Fatalf("ParseTemplates failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := struct{ Title string }{"T"}
+	if err := tmpl.ExecuteTemplate(&buf, "base.html", data); err != nil {
+		t.Fatalf("ExecuteTemplate failed: %v", err)
+	}
+	expected := "<aside>T</aside>|<aside>T</aside>|<title>T</title>"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := tmpl.ExecuteTemplate(&buf, "layouts/base.html", data); err != nil {
+		t.Fatalf("ExecuteTemplate failed: %v", err)
+	}
+	if buf.String() != "<div>T</div>" {
+		t.Errorf("Expected layout to keep its own name, got %q", buf.String())
+	}
+}
+
+// mapFileSystem serves files from memory and everything else from the OS
+type mapFileSystem struct {
+	OSFileSystem
+	files map[string]string
+}
+
+func (m *mapFileSystem) ReadFile(filename string) ([]byte, error) {
+	if content, ok := m.files[filename]; ok {
+		return []byte(content), nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func TestGoTemplateRenderer_ParseTemplates_FileSystem(t *testing.T) {
+	renderer := &GoTemplateRenderer{FS: &mapFileSystem{files: map[string]string{
+		"templates/base.html":         `{{template "partials/nav.html" .}}`,
+		"templates/partials/nav.html": `<nav>{{.Title}}</nav>`,
+	}}}
+
+	tmpl, err := renderer.ParseTemplates("templates", "templates/base.html", "templates/partials/nav.html")
+	if err != nil {
+		t.Fatalf("ParseTemplates failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "base.html", struct{ Title string }{"T"}); err != nil {
+		t.Fatalf("ExecuteTemplate failed: %v", err)
+	}
+	if buf.String() != "<nav>T</nav>" {
+		t.Errorf("Expected templates read from the file system, got %q", buf.String())
+	}
+}
+
+func TestGoTemplateRenderer_ParseTemplates_Error(t *testing.T) {
+	renderer := &GoTemplateRenderer{}
+	tempDir := t.TempDir()
+	filename := filepath.Join(tempDir, "base.html")
+	os.WriteFile(filename, []byte("{{.Broken"), 0644)
+	if _, err := renderer.ParseTemplates(tempDir, filename); err == nil {
+		t.Error("Expected error from ParseTemplates")
+	}
+	if _, err := renderer.ParseTemplates(tempDir, filepath.Join(tempDir, "missing.html")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
 func TestGoTemplateRenderer_ExecuteTemplate(t *testing.T) {
 	renderer := &GoTemplateRenderer{}
 	tmpl, _ := template.New("test").Parse("{{.Title}}")
@@ -0,0 +1,230 @@
+package infrastructure
+
+import (
+	"bytes"
+	"strings"
+)
+
+// htmlToken is a tag, a run of text or the raw content of an element such as
+// pre or script
+type htmlToken struct {
+	data []byte
+	name string // lowercase element name of a tag
+	end  bool   // closing tag
+	text bool
+	raw  bool // text copied as written
+}
+
+// htmlRawElements are the elements whose content is copied as written
+var htmlRawElements = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// htmlBlockElements are the elements next to which whitespace is not
+// rendered, so it can be dropped
+var htmlBlockElements = map[string]bool{
+	"!doctype": true, "html": true, "head": true, "body": true,
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"br": true, "caption": true, "col": true, "colgroup": true, "dd": true,
+	"details": true, "dialog": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "header": true, "hgroup": true, "hr": true, "legend": true,
+	"li": true, "main": true, "menu": true, "nav": true, "ol": true,
+	"optgroup": true, "option": true, "p": true, "pre": true, "section": true,
+	"summary": true, "table": true, "tbody": true, "td": true, "tfoot": true,
+	"th": true, "thead": true, "tr": true, "ul": true,
+}
+
+// htmlParagraphBlocks are the elements whose start tag closes an open p
+var htmlParagraphBlocks = []string{
+	"address", "article", "aside", "blockquote", "details", "dialog", "div",
+	"dl", "fieldset", "figcaption", "figure", "footer", "form", "h1", "h2",
+	"h3", "h4", "h5", "h6", "header", "hgroup", "hr", "main", "menu", "nav",
+	"ol", "p", "pre", "section", "table", "ul",
+}
+
+// htmlOptionalEnd lists, for each element whose end tag may be omitted, the
+// tags that can follow the omitted end tag, with "/" for closing tags and ""
+// for the end of the document. A closing p is also omitted before any
+// closing tag except those in htmlKeepParagraphEnd.
+var htmlOptionalEnd = map[string][]string{
+	"li":       {"li", "/ul", "/ol", "/menu"},
+	"dt":       {"dt", "dd"},
+	"dd":       {"dt", "dd", "/dl"},
+	"p":        htmlParagraphBlocks,
+	"option":   {"option", "optgroup", "/select", "/datalist", "/optgroup"},
+	"optgroup": {"optgroup", "/select"},
+	"tr":       {"tr", "/tbody", "/thead", "/tfoot", "/table"},
+	"td":       {"td", "th", "/tr"},
+	"th":       {"td", "th", "/tr"},
+	"thead":    {"tbody", "tfoot"},
+	"tbody":    {"tbody", "tfoot", "/table"},
+	"tfoot":    {"/table"},
+	"head":     {"body"},
+	"body":     {"/html", ""},
+	"html":     {""},
+}
+
+// htmlKeepParagraphEnd are the elements inside which a closing p cannot be
+// implied by their closing tag
+var htmlKeepParagraphEnd = map[string]bool{
+	"a": true, "audio": true, "del": true, "ins": true, "map": true,
+	"noscript": true, "video": true,
+}
+
+// minifyHTML removes comments, optional closing tags such as </li> and </p>,
+// whitespace next to block elements and in the head, and extra whitespace in
+// text and inside tags. The content of pre, textarea, script and style
+// elements and conditional comments are kept as written.
+func minifyHTML(src []byte) []byte {
+	tokens := tokenizeHTML(src)
+	out := make([]byte, 0, len(src))
+	inHead := false
+	for i, tok := range tokens {
+		switch {
+		case tok.raw:
+			out = append(out, tok.data...)
+		case tok.text:
+			text := collapseSpace(tok.data)
+			if inHead && len(bytes.TrimSpace(text)) == 0 {
+				continue
+			}
+			if i == 0 || htmlBlockBoundary(tokens[i-1]) {
+				text = bytes.TrimLeft(text, " ")
+			}
+			if i == len(tokens)-1 || htmlBlockBoundary(tokens[i+1]) {
+				text = bytes.TrimRight(text, " ")
+			}
+			out = append(out, text...)
+		default:
+			switch {
+			case tok.name == "head":
+				inHead = !tok.end
+			case tok.name == "body":
+				inHead = false
+			}
+			if tok.end && htmlOmitEnd(tok.name, tokens[i+1:]) {
+				continue
+			}
+			out = append(out, tok.data...)
+		}
+	}
+	return out
+}
+
+// htmlBlockBoundary reports whether tok is a tag of a block element
+func htmlBlockBoundary(tok htmlToken) bool {
+	return !tok.text && htmlBlockElements[tok.name]
+}
+
+// htmlOmitEnd reports whether the closing tag of an element can be left out
+// given the tokens that follow it
+func htmlOmitEnd(name string, rest []htmlToken) bool {
+	following, ok := htmlOptionalEnd[name]
+	if !ok {
+		return false
+	}
+	next := ""
+	for _, tok := range rest {
+		if tok.text && !tok.raw && len(bytes.TrimSpace(tok.data)) == 0 {
+			continue
+		}
+		if tok.text {
+			return false
+		}
+		next = tok.name
+		if tok.end {
+			next = "/" + next
+		}
+		break
+	}
+	if name == "p" && strings.HasPrefix(next, "/") {
+		return !htmlKeepParagraphEnd[next[1:]]
+	}
+	for _, tag := range following {
+		if tag == next {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenizeHTML splits a document into tags, text and the raw content of
+// elements such as pre. Comments are dropped, except conditional comments,
+// which are kept as raw text.
+func tokenizeHTML(src []byte) []htmlToken {
+	var tokens []htmlToken
+	var text []byte
+	flush := func() {
+		if len(text) > 0 {
+			tokens = append(tokens, htmlToken{data: text, text: true})
+			text = nil
+		}
+	}
+
+	for i := 0; i < len(src); {
+		switch {
+		case bytes.HasPrefix(src[i:], []byte("<!--")):
+			end := bytes.Index(src[i+4:], []byte("-->"))
+			if end < 0 {
+				end = len(src)
+			} else {
+				end += i + 7
+			}
+			if bytes.HasPrefix(src[i+4:], []byte("[if")) || bytes.HasPrefix(src[i+4:], []byte("<![endif]")) {
+				flush()
+				tokens = append(tokens, htmlToken{data: src[i:end], text: true, raw: true})
+			}
+			i = end
+		case src[i] == '<' && i+1 < len(src) && htmlTagStart(src[i+1]):
+			flush()
+			data, next, name, closing, _ := copyTag(nil, src, i)
+			name = bytes.ToLower(name)
+			tokens = append(tokens, htmlToken{data: data, name: string(name), end: closing})
+			i = next
+			if closing || !htmlRawElements[string(name)] {
+				continue
+			}
+			// Copy the content of raw elements up to their closing tag
+			end := indexClosingTag(src[i:], name)
+			if end < 0 {
+				end = len(src) - i
+			}
+			if end > 0 {
+				tokens = append(tokens, htmlToken{data: src[i : i+end], text: true, raw: true})
+			}
+			i += end
+		default:
+			text = append(text, src[i])
+			i++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// indexClosingTag returns the index of the first closing tag of the named
+// element in b, matching the name case-insensitively, or -1. The name must be
+// followed by the end of the tag, so </pre does not match </preview>.
+func indexClosingTag(b, name []byte) int {
+	for i := 0; ; i += 2 {
+		next := bytes.Index(b[i:], []byte("</"))
+		if next < 0 {
+			return -1
+		}
+		i += next
+		end := i + 2 + len(name)
+		if end > len(b) || !bytes.EqualFold(b[i+2:end], name) {
+			continue
+		}
+		if end == len(b) || b[end] == '>' || b[end] == '/' || isSpace(b[end]) {
+			return i
+		}
+	}
+}
+
+// htmlTagStart reports whether c can follow the < of a tag
+func htmlTagStart(c byte) bool {
+	return c == '/' || c == '!' || c == '?' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -13,10 +13,24 @@ type FileSystem interface {
 	Create(filename string) (io.WriteCloser, error)
 	MkdirAll(path string, perm fs.FileMode) error
 	RemoveAll(path string) error
+	Stat(name string) (fs.FileInfo, error)
+	Rename(oldpath, newpath string) error
+	Link(oldname, newname string) error
 }
 
 // TemplateRenderer defines the interface for template rendering
 type TemplateRenderer interface {
 	ParseFiles(filenames ...string) (*template.Template, error)
+	ParseTemplates(root string, filenames ...string) (*template.Template, error)
 	ExecuteTemplate(wr io.Writer, name string, data interface{}) error
 }
+
+// MarkdownConverter defines the interface for converting Markdown to HTML
+type MarkdownConverter interface {
+	Convert(source []byte) ([]byte, error)
+}
+
+// Minifier defines the interface for minifying files by type
+type Minifier interface {
+	Minify(ext string, content []byte) ([]byte, error)
+}
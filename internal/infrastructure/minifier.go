@@ -0,0 +1,474 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// BasicMinifier implements Minifier for HTML, CSS, JavaScript, JSON and SVG
+// without external tools. It removes comments and whitespace that cannot change how a
+// file is interpreted and leaves everything else as written: it does not
+// rename identifiers or rewrite values. Comments starting with /*! are kept,
+// as they usually hold license notices.
+type BasicMinifier struct{}
+
+// NewBasicMinifier creates a new BasicMinifier
+func NewBasicMinifier() *BasicMinifier {
+	return &BasicMinifier{}
+}
+
+// Minify minifies content of the type given by a file extension such as
+// ".css". Content of other types is returned unchanged.
+func (m *BasicMinifier) Minify(ext string, content []byte) ([]byte, error) {
+	switch strings.ToLower(ext) {
+	case ".css":
+		return minifyCSS(content), nil
+	case ".js":
+		return minifyJS(content), nil
+	case ".json":
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, content); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case ".svg":
+		return minifySVG(content), nil
+	case ".html", ".htm":
+		return minifyHTML(content), nil
+	}
+	return content, nil
+}
+
+// isSpace reports whether c is whitespace in CSS, JavaScript or XML
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// copyQuoted appends the string literal starting at src[i] to out, up to and
+// including its closing quote, and returns the index after it
+func copyQuoted(out, src []byte, i int) ([]byte, int) {
+	quote := src[i]
+	out = append(out, quote)
+	for i++; i < len(src); i++ {
+		out = append(out, src[i])
+		switch src[i] {
+		case '\\':
+			if i+1 < len(src) {
+				i++
+				out = append(out, src[i])
+			}
+		case quote:
+			return out, i + 1
+		}
+	}
+	return out, i
+}
+
+// lastByte returns the last byte of b, or 0 when it is empty
+func lastByte(b []byte) byte {
+	if len(b) == 0 {
+		return 0
+	}
+	return b[len(b)-1]
+}
+
+// minifyCSS removes comments and the whitespace around punctuation, and drops
+// the last semicolon of each block. Strings and url() values are kept as is.
+func minifyCSS(src []byte) []byte {
+	out := make([]byte, 0, len(src))
+	space := false
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case isSpace(c):
+			space = true
+			i++
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(src)
+			} else {
+				end += i + 4
+			}
+			if i+2 < len(src) && src[i+2] == '!' {
+				out = append(out, src[i:end]...)
+			} else {
+				space = space || len(out) > 0
+			}
+			i = end
+			continue
+		}
+
+		if space && len(out) > 0 && !bytes.HasSuffix(out, []byte("*/")) && !strings.ContainsRune("{};,>~(:", rune(lastByte(out))) && !strings.ContainsRune("{};,>~)!", rune(c)) {
+			out = append(out, ' ')
+		}
+		space = false
+
+		switch {
+		case c == '"' || c == '\'':
+			out, i = copyQuoted(out, src, i)
+		case c == '(' && len(out) >= 3 && bytes.EqualFold(out[len(out)-3:], []byte("url")):
+			end := bytes.IndexByte(src[i:], ')')
+			if end < 0 {
+				// Copy an unterminated url( as written
+				out = append(out, src[i:]...)
+				i = len(src)
+				continue
+			}
+			value := bytes.TrimSpace(src[i+1 : i+end])
+			if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+				// Quoted URLs are copied as strings
+				out = append(out, '(')
+				i++
+				continue
+			}
+			out = append(out, '(')
+			out = append(out, value...)
+			out = append(out, ')')
+			i += end + 1
+		case c == '}':
+			if lastByte(out) == ';' {
+				out = out[:len(out)-1]
+			}
+			out = append(out, c)
+			i++
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return out
+}
+
+// jsRegexKeywords are the keywords after which a slash starts a regular
+// expression rather than a division
+var jsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true,
+}
+
+// isWordByte reports whether c can be part of a JavaScript identifier,
+// keyword or number
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c == '\\' || c >= 0x80 ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// minifyJS removes comments, indentation and the whitespace between tokens
+// that do not need it. Line breaks are kept wherever automatic semicolon
+// insertion could depend on them. Strings, template literals and regular
+// expressions are kept as is.
+func minifyJS(src []byte) []byte {
+	out := make([]byte, 0, len(src))
+	i := 0
+	if bytes.HasPrefix(src, []byte("#!")) {
+		// Keep the hashbang line of scripts
+		end := bytes.IndexByte(src, '\n')
+		if end < 0 {
+			return src
+		}
+		out = append(out, src[:end+1]...)
+		i = end + 1
+	}
+
+	var templates []int // brace depth of the code around each open ${
+	depth := 0
+	space, newline := false, false
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n' || c == '\r':
+			newline = true
+			i++
+			continue
+		case isSpace(c):
+			space = true
+			i++
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(src)
+			} else {
+				end += i + 4
+			}
+			comment := src[i:end]
+			if bytes.HasPrefix(comment, []byte("/*!")) {
+				if len(out) > 0 {
+					out = append(out, '\n')
+				}
+				out = append(out, comment...)
+				newline = true
+			} else if bytes.ContainsAny(comment, "\r\n") {
+				newline = true
+			} else {
+				space = true
+			}
+			i = end
+			continue
+		}
+
+		prev := lastByte(out)
+		switch {
+		case len(out) == 0:
+		case newline && !strings.ContainsRune("{;,([", rune(prev)) && !strings.ContainsRune(")]},;.", rune(c)):
+			out = append(out, '\n')
+		case (space || newline) && needsJSSpace(prev, c):
+			out = append(out, ' ')
+		}
+		space, newline = false, false
+
+		switch {
+		case c == '"' || c == '\'':
+			out, i = copyQuoted(out, src, i)
+		case c == '`':
+			out, i = copyTemplate(out, src, i+1, append([]byte(nil), '`'))
+			if i > 0 && i <= len(src) && bytes.HasSuffix(out, []byte("${")) {
+				templates = append(templates, depth)
+				depth = 0
+			}
+		case c == '{':
+			depth++
+			out = append(out, c)
+			i++
+		case c == '}' && depth == 0 && len(templates) > 0:
+			depth = templates[len(templates)-1]
+			templates = templates[:len(templates)-1]
+			out, i = copyTemplate(out, src, i+1, []byte{'}'})
+			if bytes.HasSuffix(out, []byte("${")) {
+				templates = append(templates, depth)
+				depth = 0
+			}
+		case c == '}':
+			depth--
+			out = append(out, c)
+			i++
+		case c == '/' && regexAllowed(out):
+			out, i = copyRegex(out, src, i)
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return out
+}
+
+// needsJSSpace reports whether a space between prev and next must be kept, as
+// between two words or in "a + +b"
+func needsJSSpace(prev, next byte) bool {
+	switch {
+	case isWordByte(prev) && isWordByte(next):
+		return true
+	case '0' <= prev && prev <= '9' && next == '.':
+		return true
+	case (prev == '+' || prev == '-' || prev == '/') && next == prev:
+		return true
+	}
+	return false
+}
+
+// copyTemplate appends the part of a template literal starting at src[i] to
+// out after prefix, up to and including its closing backtick or the next ${,
+// and returns the index after it
+func copyTemplate(out, src []byte, i int, prefix []byte) ([]byte, int) {
+	out = append(out, prefix...)
+	for ; i < len(src); i++ {
+		out = append(out, src[i])
+		switch src[i] {
+		case '\\':
+			if i+1 < len(src) {
+				i++
+				out = append(out, src[i])
+			}
+		case '`':
+			return out, i + 1
+		case '$':
+			if i+1 < len(src) && src[i+1] == '{' {
+				return append(out, '{'), i + 2
+			}
+		}
+	}
+	return out, i
+}
+
+// regexAllowed reports whether a slash after out starts a regular expression:
+// after an operator, an opening bracket, a keyword such as return, or a
+// closing brace, but not after an identifier, a number or a closing
+// parenthesis or bracket
+func regexAllowed(out []byte) bool {
+	end := len(out)
+	for end > 0 && isSpace(out[end-1]) {
+		end--
+	}
+	if end == 0 {
+		return true
+	}
+	prev := out[end-1]
+	switch {
+	case prev == ')' || prev == ']':
+		return false
+	case isWordByte(prev):
+		start := end
+		for start > 0 && isWordByte(out[start-1]) {
+			start--
+		}
+		return jsRegexKeywords[string(out[start:end])]
+	}
+	return true
+}
+
+// copyRegex appends the regular expression literal starting at src[i] to
+// out, without its flags, and returns the index after it
+func copyRegex(out, src []byte, i int) ([]byte, int) {
+	out = append(out, '/')
+	class := false
+	for i++; i < len(src); i++ {
+		c := src[i]
+		out = append(out, c)
+		switch {
+		case c == '\\' && i+1 < len(src):
+			i++
+			out = append(out, src[i])
+		case c == '[':
+			class = true
+		case c == ']':
+			class = false
+		case c == '/' && !class:
+			return out, i + 1
+		case c == '\n':
+			// Not a regular expression after all; leave the rest to the caller
+			return out, i + 1
+		}
+	}
+	return out, i
+}
+
+// svgPreserve are the SVG elements whose text is kept as written
+var svgPreserve = map[string]bool{
+	"text": true, "tspan": true, "textPath": true, "style": true, "script": true,
+	"title": true, "desc": true, "pre": true,
+}
+
+// minifySVG removes comments, the whitespace between tags and the extra
+// whitespace inside tags. Text of text, style and script elements and CDATA
+// sections is kept as written.
+func minifySVG(src []byte) []byte {
+	out := make([]byte, 0, len(src))
+	preserve := 0
+	for i := 0; i < len(src); {
+		switch {
+		case bytes.HasPrefix(src[i:], []byte("<!--")):
+			end := bytes.Index(src[i:], []byte("-->"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case bytes.HasPrefix(src[i:], []byte("<![CDATA[")):
+			end := bytes.Index(src[i:], []byte("]]>"))
+			if end < 0 {
+				end = len(src) - i - 3
+			}
+			out = append(out, src[i:i+end+3]...)
+			i += end + 3
+		case src[i] == '<':
+			var name []byte
+			var closing, selfClosing bool
+			out, i, name, closing, selfClosing = copyTag(out, src, i)
+			if svgPreserve[string(name)] && !selfClosing {
+				if closing {
+					preserve--
+				} else {
+					preserve++
+				}
+			}
+		default:
+			end := bytes.IndexByte(src[i:], '<')
+			if end < 0 {
+				end = len(src) - i
+			}
+			text := src[i : i+end]
+			switch {
+			case preserve > 0:
+				out = append(out, text...)
+			case len(bytes.TrimSpace(text)) > 0:
+				out = append(out, collapseSpace(text)...)
+			}
+			i += end
+		}
+	}
+	return bytes.TrimSpace(out)
+}
+
+// copyTag appends the tag starting at src[i] to out with runs of whitespace
+// outside attribute values collapsed, and without whitespace before its end.
+// It returns the index after the tag, the element name and whether the tag is
+// a closing or self-closing tag.
+func copyTag(out, src []byte, i int) ([]byte, int, []byte, bool, bool) {
+	start := len(out)
+	space := false
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'':
+			if space && lastByte(out) != '=' {
+				out = append(out, ' ')
+				space = false
+			}
+			out, i = copyQuoted(out, src, i)
+			continue
+		case isSpace(c):
+			space = true
+			i++
+			continue
+		case c == '>':
+		case c == '/' && i+1 < len(src) && src[i+1] == '>' && (lastByte(out) == '"' || lastByte(out) == '\''):
+			// The space is kept after unquoted values, which would take the slash
+		case space && !strings.ContainsRune("<=", rune(lastByte(out))) && c != '=':
+			out = append(out, ' ')
+		}
+		space = false
+		out = append(out, c)
+		i++
+		if c == '>' {
+			break
+		}
+	}
+
+	tag := out[start:]
+	closing := bytes.HasPrefix(tag, []byte("</"))
+	selfClosing := bytes.HasSuffix(tag, []byte("/>")) || bytes.HasPrefix(tag, []byte("<?")) || bytes.HasPrefix(tag, []byte("<!"))
+	name := bytes.TrimLeft(tag, "</")
+	if end := bytes.IndexAny(name, " />"); end >= 0 {
+		name = name[:end]
+	}
+	return out, i, name, closing, selfClosing
+}
+
+// collapseSpace replaces every run of whitespace in text with a single space
+func collapseSpace(text []byte) []byte {
+	out := make([]byte, 0, len(text))
+	space := false
+	for _, c := range text {
+		if isSpace(c) {
+			space = true
+			continue
+		}
+		if space {
+			out = append(out, ' ')
+			space = false
+		}
+		out = append(out, c)
+	}
+	if space {
+		out = append(out, ' ')
+	}
+	return out
+}
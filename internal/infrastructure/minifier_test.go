@@ -0,0 +1,130 @@
+package infrastructure
+
+import (
+	"testing"
+)
+
+func TestBasicMinifier_Minify(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		source   string
+		expected string
+	}{
+		{
+			"css",
+			".css",
+			"/* Layout */\nbody ,\nhtml {\n  margin : 0 auto;\n  font-family: \"Open  Sans\", sans-serif;\n}\n\n.nav > a:hover  ~ span { color: red !important; }\n",
+			`body,html{margin :0 auto;font-family:"Open  Sans",sans-serif}.nav>a:hover~span{color:red!important}`,
+		},
+		{
+			"css keeps license comments, url() and spaces before parentheses",
+			".css",
+			"/*! v1 */\n@media screen and (max-width: 600px) {\n  a { background: url( img/a b.png ); width: calc(100% - 2px) }\n}\n",
+			"/*! v1 */@media screen and (max-width:600px){a{background:url(img/a b.png);width:calc(100% - 2px)}}",
+		},
+		{
+			"css with an unterminated comment",
+			".css",
+			"body {}\n/*",
+			"body{}",
+		},
+		{
+			"css with an unterminated url(",
+			".css",
+			"a { background: url(",
+			"a{background:url(",
+		},
+		{
+			"js",
+			".js",
+			"// Greeting\nfunction greet(name) {\n  /* say hi */\n  const message = 'Hello, ' + name;\n  return message\n}\nlet a = b + +c\nlet d = e - -f\n",
+			"function greet(name){const message='Hello, '+name;return message}\nlet a=b+ +c\nlet d=e- -f",
+		},
+		{
+			"js keeps strings, templates and regular expressions",
+			".js",
+			"const s = \"a // b /* c */\";\nconst t = `x  ${ y ? `${ z }` : '}' }  // w`;\nconst r = /[/]+ \\/ /g.test(s) / 2\nreturn /a  b/\n",
+			"const s=\"a // b /* c */\";const t=`x  ${y?`${z}`:'}'}  // w`;const r=/[/]+ \\/ /g.test(s)/2\nreturn/a  b/",
+		},
+		{
+			"json",
+			".json",
+			"{\n  \"name\": \"a  b\",\n  \"list\": [1, 2]\n}\n",
+			`{"name":"a  b","list":[1,2]}`,
+		},
+		{
+			"svg",
+			".svg",
+			"<?xml version=\"1.0\"?>\n<!-- icon -->\n<svg xmlns=\"http://www.w3.org/2000/svg\"\n     viewBox=\"0 0 10  10\" >\n  <title>An  icon</title>\n  <path d=\"M0 0 L10  10\" />\n  <text x=\"1\">  Hi  there </text>\n</svg>\n",
+			`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10  10"><title>An  icon</title><path d="M0 0 L10  10"/><text x="1">  Hi  there </text></svg>`,
+		},
+		{
+			"html",
+			".html",
+			"<!DOCTYPE html>\n<html lang=\"en\">\n  <head>\n    <meta charset=\"utf-8\">\n    <title>A  page</title>\n    <!-- styles -->\n    <link rel=\"stylesheet\"   href=\"/a.css\" >\n  </head>\n  <body class = \"home\">\n    <ul>\n      <li>One</li>\n      <li><a href=\"/\">Two</a> and  <b>three</b></li>\n    </ul>\n    <p>First\n      paragraph</p>\n    <p>Second <br />\n      line</p>\n    <a href=\"/\"><p>Kept</p></a>\n    <table>\n      <tr><td>1</td><td>2</td></tr>\n    </table>\n  </body>\n</html>\n",
+			`<!DOCTYPE html><html lang="en"><head><meta charset="utf-8"><title>A page</title><link rel="stylesheet" href="/a.css"><body class="home"><ul><li>One<li><a href="/">Two</a> and <b>three</b></ul><p>First paragraph<p>Second<br />line</p><a href="/"><p>Kept</p></a><table><tr><td>1<td>2</table>`,
+		},
+		{
+			"html keeps pre, textarea, scripts and conditional comments",
+			".html",
+			"<div>\n  <pre>\n  code  <b>here</b>\n</pre>\n  <textarea>  a\n  b</textarea>\n  <script>\n    if (a < b) { x = \"</p>  \" }\n  </script>\n  <!--[if IE]><p>Old</p><![endif]-->\n  <span>a</span> <span>b</span>\n</div>\n",
+			"<div><pre>\n  code  <b>here</b>\n</pre><textarea>  a\n  b</textarea> <script>\n    if (a < b) { x = \"</p>  \" }\n  </script> <!--[if IE]><p>Old</p><![endif]--> <span>a</span> <span>b</span></div>",
+		},
+		{
+			"html matches closing tags of raw elements in any case",
+			".html",
+			"<PRE>  a\n</pre>\n<Script>  b  </SCRIPT>\n",
+			"<PRE>  a\n</pre><Script>  b  </SCRIPT>",
+		},
+		{
+			"html ends raw elements only at their own closing tag",
+			".html",
+			"<pre>  a </preview>  b </pre >\n<p>c</p>\n",
+			"<pre>  a </preview>  b </pre><p>c</p>",
+		},
+		{
+			"other types are unchanged",
+			".txt",
+			"  keep  me  ",
+			"  keep  me  ",
+		},
+	}
+
+	minifier := NewBasicMinifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := minifier.Minify(tt.ext, []byte(tt.source))
+			if err != nil {
+				t.Fatalf("Minify failed: %v", err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("Minify(%s) =\n%q\nexpected\n%q", tt.ext, out, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBasicMinifier_Truncated(t *testing.T) {
+	sources := map[string]string{
+		".css":  "a { background: url( 'x.png' ) } /*! v1 */ b { content: \"}\" } /* c */",
+		".js":   "const a = `x ${ b } y` /* c */ + 'd' // e\nreturn /[/]+/g.test(s)",
+		".svg":  "<svg a=\"1\"><!-- c --><![CDATA[ x ]]><text> t </text></svg>",
+		".html": "<!DOCTYPE html><p class=\"a\">x<!-- c --><pre> y </pre><script>z</script><!--[if IE]>o<![endif]-->",
+	}
+	minifier := NewBasicMinifier()
+	for ext, source := range sources {
+		// Minifying any prefix of a file must not panic
+		for i := 0; i <= len(source); i++ {
+			if _, err := minifier.Minify(ext, []byte(source[:i])); err != nil {
+				t.Errorf("Minify(%s, %q) failed: %v", ext, source[:i], err)
+			}
+		}
+	}
+}
+
+func TestBasicMinifier_InvalidJSON(t *testing.T) {
+	if _, err := NewBasicMinifier().Minify(".json", []byte("{,}")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
@@ -34,3 +34,18 @@ func (fs *OSFileSystem) MkdirAll(path string, perm fs.FileMode) error {
 func (fs *OSFileSystem) RemoveAll(path string) error {
 	return os.RemoveAll(path)
 }
+
+// Stat returns a FileInfo describing the named file
+func (fs *OSFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Rename renames (moves) oldpath to newpath
+func (fs *OSFileSystem) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// Link creates newname as a hard link to the oldname file
+func (fs *OSFileSystem) Link(oldname, newname string) error {
+	return os.Link(oldname, newname)
+}
@@ -87,3 +87,56 @@ func TestOSFileSystem_RemoveAll(t *testing.T) {
 		t.Error("Directory not removed")
 	}
 }
+
+func TestOSFileSystem_Stat(t *testing.T) {
+	fileSys := &OSFileSystem{}
+	tempFile := filepath.Join(t.TempDir(), "test.txt")
+	if _, err := fileSys.Stat(tempFile); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error, got %v", err)
+	}
+	os.WriteFile(tempFile, []byte("test"), 0644)
+	info, err := fileSys.Stat(tempFile)
+	if err != nil {
+		t.Errorf("Stat failed: %v", err)
+	}
+	if info == nil || info.Size() != 4 {
+		t.Error("Stat returned wrong info")
+	}
+}
+
+func TestOSFileSystem_Rename(t *testing.T) {
+	fileSys := &OSFileSystem{}
+	tempDir := t.TempDir()
+	oldDir := filepath.Join(tempDir, "old")
+	newDir := filepath.Join(tempDir, "new")
+	os.MkdirAll(oldDir, 0755)
+	os.WriteFile(filepath.Join(oldDir, "file.txt"), []byte("test"), 0644)
+
+	if err := fileSys.Rename(oldDir, newDir); err != nil {
+		t.Errorf("Rename failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(newDir, "file.txt")); err != nil {
+		t.Error("File not moved")
+	}
+	if _, err := os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Error("Old directory still exists")
+	}
+}
+
+func TestOSFileSystem_Link(t *testing.T) {
+	fileSys := &OSFileSystem{}
+	tempDir := t.TempDir()
+	oldFile := filepath.Join(tempDir, "old.txt")
+	newFile := filepath.Join(tempDir, "new.txt")
+	os.WriteFile(oldFile, []byte("test"), 0644)
+
+	if err := fileSys.Link(oldFile, newFile); err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+	if content, err := os.ReadFile(newFile); err != nil || string(content) != "test" {
+		t.Errorf("Linked file = %q, %v", content, err)
+	}
+	if err := fileSys.Link(filepath.Join(tempDir, "missing.txt"), newFile); err == nil {
+		t.Error("Expected an error linking a missing file")
+	}
+}
@@ -26,6 +26,17 @@ type Meta struct {
 	TwitterDescription string                 `yaml:"twitter_description" json:"twitter_description"`
 	TwitterImage       string                 `yaml:"twitter_image" json:"twitter_image"`
 	JsonLd             map[string]interface{} `yaml:"jsonld" json:"jsonld"`
+
+	// Alternates are the versions of the page in every language of the site,
+	// set by the builder rather than front matter
+	Alternates []Alternate `yaml:"-" json:"-"`
+}
+
+// Alternate is a language version of a page, emitted as
+// <link rel="alternate" hreflang="{{.Lang}}" href="{{.URL}}">
+type Alternate struct {
+	Lang string // language code, or x-default for the default language
+	URL  string // absolute when the site has a base URL
 }
 
 // Validate checks the meta fields for SEO best practices and constraints.
@@ -94,30 +105,51 @@ func Merge(siteMeta, pageMeta Meta) Meta {
 // parseYAMLFrontMatter extracts and parses YAML front matter from content.
 // Returns the parsed meta, body content without front matter, and any error.
 func parseYAMLFrontMatter(content string) (Meta, string, error) {
+	meta, _, body, err := parseYAMLFrontMatterParams(content)
+	return meta, body, err
+}
+
+// parseYAMLFrontMatterParams extracts and parses YAML front matter from content.
+// Returns the parsed meta, all front matter keys, body content without front
+// matter, and any error.
+func parseYAMLFrontMatterParams(content string) (Meta, map[string]interface{}, string, error) {
 	var meta Meta
 	if !strings.HasPrefix(content, "---\n") {
-		return meta, content, nil // No YAML front matter found
+		return meta, nil, content, nil // No YAML front matter found
 	}
 
 	parts := strings.SplitN(content, "---\n", 3)
 	if len(parts) < 3 {
-		return meta, content, fmt.Errorf("invalid YAML front matter: missing closing ---")
+		return meta, nil, content, fmt.Errorf("invalid YAML front matter: missing closing ---")
 	}
 
+	var params map[string]interface{}
+	if err := yaml.Unmarshal([]byte(parts[1]), &params); err != nil {
+		return meta, nil, content, fmt.Errorf("failed to parse YAML front matter: %w", err)
+	}
 	if err := yaml.Unmarshal([]byte(parts[1]), &meta); err != nil {
-		return meta, content, fmt.Errorf("failed to parse YAML front matter: %w", err)
+		return meta, nil, content, fmt.Errorf("failed to parse YAML front matter: %w", err)
 	}
 
 	body := strings.TrimLeft(parts[2], "\n")
-	return meta, body, nil
+	return meta, params, body, nil
 }
 
 // parseJSONFrontMatter extracts and parses JSON front matter from content.
 // Returns the parsed meta, body content without front matter, and any error.
 func parseJSONFrontMatter(content string) (Meta, string, error) {
+	meta, _, body, err := parseJSONFrontMatterParams(content)
+	return meta, body, err
+}
+
+// parseJSONFrontMatterParams extracts and parses JSON front matter from content.
+// Content starting with a template action ("{{") is not front matter.
+// Returns the parsed meta, all front matter keys, body content without front
+// matter, and any error.
+func parseJSONFrontMatterParams(content string) (Meta, map[string]interface{}, string, error) {
 	var meta Meta
-	if !strings.HasPrefix(content, "{\n") && !strings.HasPrefix(content, "{") {
-		return meta, content, nil // No JSON front matter found
+	if !strings.HasPrefix(content, "{") || strings.HasPrefix(content, "{{") {
+		return meta, nil, content, nil // No JSON front matter found
 	}
 
 	end := strings.Index(content, "}\n")
@@ -125,40 +157,54 @@ func parseJSONFrontMatter(content string) (Meta, string, error) {
 		end = strings.Index(content, "}")
 	}
 	if end == -1 {
-		return meta, content, fmt.Errorf("invalid JSON front matter: missing closing }")
+		return meta, nil, content, fmt.Errorf("invalid JSON front matter: missing closing }")
 	}
 
 	jsonPart := content[:end+1]
+	var params map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonPart), &params); err != nil {
+		return meta, nil, content, fmt.Errorf("failed to parse JSON front matter: %w", err)
+	}
 	if err := json.Unmarshal([]byte(jsonPart), &meta); err != nil {
-		return meta, content, fmt.Errorf("failed to parse JSON front matter: %w", err)
+		return meta, nil, content, fmt.Errorf("failed to parse JSON front matter: %w", err)
 	}
 
 	body := strings.TrimLeft(content[end+1:], "\n")
-	return meta, body, nil
+	return meta, params, body, nil
 }
 
 // ParseFrontMatter extracts YAML or JSON front matter from page content.
 // It tries YAML first, then JSON, and falls back to returning the content as-is.
 // Returns the parsed meta, the body content without front matter, and any error.
 func ParseFrontMatter(content string) (Meta, string, error) {
+	meta, _, body, err := ParseFrontMatterParams(content)
+	return meta, body, err
+}
+
+// ParseFrontMatterParams extracts YAML or JSON front matter from page content
+// like ParseFrontMatter, additionally returning every front matter key so that
+// non-SEO settings such as the page layout can be read.
+// Returns the parsed meta, the front matter keys, the body content without
+// front matter, and any error.
+func ParseFrontMatterParams(content string) (Meta, map[string]interface{}, string, error) {
 	// Try YAML front matter first
-	if meta, body, err := parseYAMLFrontMatter(content); err != nil {
-		return Meta{}, content, err
-	} else if meta.Title != "" || meta.Description != "" || len(meta.JsonLd) > 0 {
+	if meta, params, body, err := parseYAMLFrontMatterParams(content); err != nil {
+		return Meta{}, nil, content, err
+	} else if len(params) > 0 {
 		// Check if we actually parsed something (not just empty front matter)
-		return meta, body, nil
+		return meta, params, body, nil
 	}
 
 	// Try JSON front matter
-	if meta, body, err := parseJSONFrontMatter(content); err != nil {
-		return Meta{}, content, err
-	} else if meta.Title != "" || meta.Description != "" || len(meta.JsonLd) > 0 {
+	if meta, params, body, err := parseJSONFrontMatterParams(content); err != nil {
+		return Meta{}, nil, content, err
+	} else if len(params) > 0 {
 		// Check if we actually parsed something
-		return meta, body, nil
+		return meta, params, body, nil
 	}
 
 	// No front matter found, return content as-is
-	return Meta{}, content, nil
+	return Meta{}, map[string]interface{}{}, content, nil
 }
 
 // LoadSiteMeta extracts site-wide meta configuration from the config map.
@@ -172,3 +218,13 @@ func LoadSiteMeta(config map[string]interface{}) Meta {
 	}
 	return meta
 }
+
+// LoadLanguageMeta extracts the site-wide meta configuration of a language.
+// The "meta" key of the language under "languages" overrides the site-wide
+// defaults loaded by LoadSiteMeta.
+func LoadLanguageMeta(config map[string]interface{}, lang string) Meta {
+	siteMeta := LoadSiteMeta(config)
+	languages, _ := config["languages"].(map[string]interface{})
+	language, _ := languages[lang].(map[string]interface{})
+	return Merge(siteMeta, LoadSiteMeta(language))
+}
@@ -31,6 +31,38 @@ description: "Test Description"
 	}
 }
 
+func TestParseFrontMatterParams(t *testing.T) {
+	content := `---
+layout: docs
+weight: 3
+---
+<p>Docs</p>`
+
+	meta, params, body, err := ParseFrontMatterParams(content)
+	if err != nil {
+		t.Fatalf("ParseFrontMatterParams failed: %v", err)
+	}
+	if meta.Title != "" {
+		t.Errorf("Expected empty title, got '%s'", meta.Title)
+	}
+	if params["layout"] != "docs" || params["weight"] != 3 {
+		t.Errorf("Expected layout and weight params, got %v", params)
+	}
+	if body != "<p>Docs</p>" {
+		t.Errorf("Expected body '<p>Docs</p>', got '%s'", body)
+	}
+
+	// Template actions are not JSON front matter
+	templateContent := `{{.Title}}<p>Body</p>`
+	_, params2, body2, err2 := ParseFrontMatterParams(templateContent)
+	if err2 != nil {
+		t.Fatalf("ParseFrontMatterParams failed on template content: %v", err2)
+	}
+	if len(params2) != 0 || body2 != templateContent {
+		t.Errorf("Expected unchanged body and no params, got %v, '%s'", params2, body2)
+	}
+}
+
 func TestParseYAMLFrontMatter(t *testing.T) {
 	// Valid YAML front matter
 	yamlContent := `---
@@ -161,3 +193,28 @@ func TestValidate(t *testing.T) {
 		t.Error("Expected validation error for og_image not under /assets/")
 	}
 }
+
+func TestLoadLanguageMeta(t *testing.T) {
+	config := map[string]interface{}{
+		"meta": map[string]interface{}{
+			"title":       "My Site",
+			"description": "A site",
+		},
+		"languages": map[string]interface{}{
+			"de": map[string]interface{}{
+				"name": "Deutsch",
+				"meta": map[string]interface{}{"description": "Eine Seite"},
+			},
+			"en": map[string]interface{}{"name": "English"},
+		},
+	}
+
+	de := LoadLanguageMeta(config, "de")
+	if de.Title != "My Site" || de.Description != "Eine Seite" {
+		t.Errorf("Expected German description over site title, got %+v", de)
+	}
+	en := LoadLanguageMeta(config, "en")
+	if en.Title != "My Site" || en.Description != "A site" {
+		t.Errorf("Expected site meta for a language without meta, got %+v", en)
+	}
+}